@@ -0,0 +1,163 @@
+package arweave
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// winstonDecimals is the number of decimals of an AR amount, 1 AR = 1e12 winston
+const winstonDecimals = 12
+
+var winstonPerAR = new(big.Int).Exp(big.NewInt(10), big.NewInt(winstonDecimals), nil)
+
+// Amount is an exact amount of AR, stored in winston. The zero value is 0 and
+// amounts are immutable, arithmetic returns new amounts
+type Amount struct {
+	winston *big.Int
+}
+
+// NewWinston creates an amount of w winston
+func NewWinston(w int64) Amount {
+	return Amount{winston: big.NewInt(w)}
+}
+
+// WinstonFromBig creates an amount of w winston
+func WinstonFromBig(w *big.Int) Amount {
+	if w == nil {
+		return Amount{}
+	}
+	return Amount{winston: new(big.Int).Set(w)}
+}
+
+// ParseWinston parses an integer amount of winston, such as the ones returned by
+// the nodes. An empty string is 0
+func ParseWinston(s string) (Amount, error) {
+	if s == "" {
+		return Amount{}, nil
+	}
+	w, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return Amount{}, fmt.Errorf("invalid winston amount %q", s)
+	}
+	return Amount{winston: w}, nil
+}
+
+// ParseAR parses a decimal amount of AR, such as "1.5", which can have up to 12
+// decimals. It is converted to winston exactly
+func ParseAR(s string) (Amount, error) {
+	str := s
+	negative := strings.HasPrefix(str, "-")
+	if negative {
+		str = str[1:]
+	}
+	parts := strings.SplitN(str, ".", 2)
+	integer, decimals := parts[0], ""
+	if len(parts) == 2 {
+		decimals = parts[1]
+	}
+	if integer == "" && decimals == "" || len(decimals) > winstonDecimals || !isDigits(integer) || !isDigits(decimals) {
+		return Amount{}, fmt.Errorf("invalid AR amount %q", s)
+	}
+	w, _ := new(big.Int).SetString("0"+integer+decimals+strings.Repeat("0", winstonDecimals-len(decimals)), 10)
+	if negative {
+		w.Neg(w)
+	}
+	return Amount{winston: w}, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Winston returns a copy of the amount in winston
+func (a Amount) Winston() *big.Int {
+	if a.winston == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.winston)
+}
+
+// String returns the amount in winston, the way nodes expect it
+func (a Amount) String() string {
+	return a.Winston().String()
+}
+
+// AR returns the amount in AR, without trailing zeros, such as "1.5"
+func (a Amount) AR() string {
+	w := a.Winston()
+	sign := ""
+	if w.Sign() < 0 {
+		sign = "-"
+		w.Neg(w)
+	}
+	integer, decimals := new(big.Int).QuoRem(w, winstonPerAR, new(big.Int))
+	if decimals.Sign() == 0 {
+		return sign + integer.String()
+	}
+	d := fmt.Sprintf("%0*s", winstonDecimals, decimals.String())
+	return sign + integer.String() + "." + strings.TrimRight(d, "0")
+}
+
+// Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+	return Amount{winston: new(big.Int).Add(a.Winston(), b.Winston())}
+}
+
+// Sub returns a - b
+func (a Amount) Sub(b Amount) Amount {
+	return Amount{winston: new(big.Int).Sub(a.Winston(), b.Winston())}
+}
+
+// Mul returns a * n
+func (a Amount) Mul(n int64) Amount {
+	return Amount{winston: new(big.Int).Mul(a.Winston(), big.NewInt(n))}
+}
+
+// Div returns a / n, rounded towards zero
+func (a Amount) Div(n int64) Amount {
+	return Amount{winston: new(big.Int).Quo(a.Winston(), big.NewInt(n))}
+}
+
+// Cmp compares a and b and returns -1 if a < b, 0 if a == b and 1 if a > b
+func (a Amount) Cmp(b Amount) int {
+	return a.Winston().Cmp(b.Winston())
+}
+
+// Sign returns -1, 0 or 1 depending on the sign of the amount
+func (a Amount) Sign() int {
+	return a.Winston().Sign()
+}
+
+// IsZero returns true if the amount is 0
+func (a Amount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// MarshalJSON encodes the amount as a string of winston, the way nodes do
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON decodes an amount of winston given either as a string or a number
+func (a *Amount) UnmarshalJSON(input []byte) error {
+	s := string(input)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	parsed, err := ParseWinston(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
@@ -0,0 +1,82 @@
+package arweave
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAR(t *testing.T) {
+	cases := []struct {
+		ar      string
+		winston string
+	}{
+		{"1", "1000000000000"},
+		{"1.5", "1500000000000"},
+		{"0.000000000001", "1"},
+		{".25", "250000000000"},
+		{"123456789.123456789012", "123456789123456789012"},
+		{"-2.1", "-2100000000000"},
+	}
+	for _, c := range cases {
+		a, err := ParseAR(c.ar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, c.winston, a.String(), "wrong winston amount for %s AR", c.ar)
+	}
+
+	for _, invalid := range []string{"", ".", "1.0000000000001", "1e3", "1,5", "0x10", "1.-5"} {
+		_, err := ParseAR(invalid)
+		assert.Error(t, err, "%q should not parse", invalid)
+	}
+}
+
+func TestFormatAR(t *testing.T) {
+	cases := map[string]string{
+		"0":                     "0",
+		"1":                     "0.000000000001",
+		"1000000000000":         "1",
+		"1500000000000":         "1.5",
+		"-2100000000000":        "-2.1",
+		"123456789123456789012": "123456789.123456789012",
+	}
+	for winston, ar := range cases {
+		a, err := ParseWinston(winston)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, ar, a.AR())
+	}
+}
+
+func TestAmountArithmetic(t *testing.T) {
+	a := NewWinston(1000)
+	b := NewWinston(300)
+	assert.Equal(t, "1300", a.Add(b).String())
+	assert.Equal(t, "-700", b.Sub(a).String())
+	assert.Equal(t, "1100", a.Mul(110).Div(100).String())
+	assert.Equal(t, 1, a.Cmp(b))
+	assert.True(t, Amount{}.IsZero(), "the zero value should be 0")
+	assert.Equal(t, "1000", a.String(), "arithmetic should not modify its operands")
+}
+
+func TestAmountJSON(t *testing.T) {
+	v := struct {
+		Quantity Amount `json:"quantity"`
+		Reward   Amount `json:"reward"`
+	}{}
+	err := json.Unmarshal([]byte(`{"quantity":"2500000000000000000000","reward":1234}`), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "2500000000", v.Quantity.AR())
+	assert.Equal(t, "1234", v.Reward.String())
+
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{"quantity":"2500000000000000000000","reward":"1234"}`, string(encoded))
+}
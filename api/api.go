@@ -9,19 +9,46 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Dev43/arweave-go"
 	"github.com/Dev43/arweave-go/tx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Client struct
 type Client struct {
-	client *http.Client
-	url    string
+	client      *http.Client
+	url         string
+	middlewares []Middleware
+	logger      arweave.Logger
+	metrics     arweave.MetricsCollector
+
+	tracerProvider trace.TracerProvider
 }
 
 // Dial creates a new arweave client
 func Dial(url string) (*Client, error) {
-	return &Client{client: new(http.Client), url: url}, nil
+	return &Client{client: new(http.Client), url: url, logger: arweave.NopLogger{}}, nil
+}
+
+// SetLogger sets the logger receiving the requests and responses of the client
+func (c *Client) SetLogger(l arweave.Logger) {
+	if l == nil {
+		l = arweave.NopLogger{}
+	}
+	c.logger = l
+}
+
+// SetMetrics sets the collector receiving the measurements of the client's requests
+func (c *Client) SetMetrics(m arweave.MetricsCollector) {
+	c.metrics = m
 }
 
 // GetData requests the data of a transaction
@@ -68,7 +95,49 @@ func (c *Client) GetTransaction(ctx context.Context, txID string) (*tx.Transacti
 	return &tx, nil
 }
 
-// GetTransaction requests the information of a transaction
+// GetUnconfirmedTransaction requests a transaction which may still be pending,
+// which GetTransaction does not return
+func (c *Client) GetUnconfirmedTransaction(ctx context.Context, txID string) (*tx.Transaction, error) {
+	body, err := c.get(ctx, fmt.Sprintf("unconfirmed_tx/%s", txID))
+	if err != nil {
+		return nil, err
+	}
+	tx := tx.Transaction{}
+	err = json.Unmarshal(body, &tx)
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// GetTransactionStatus requests the status of a transaction. A transaction which
+// is unknown to the node is reported with the StatusNotFound state, not as an error
+func (c *Client) GetTransactionStatus(ctx context.Context, txID string) (*TransactionStatus, error) {
+	body, err := c.get(ctx, fmt.Sprintf("tx/%s/status", txID))
+	if httpErr, ok := err.(*HTTPError); ok {
+		switch httpErr.StatusCode {
+		case http.StatusNotFound:
+			return &TransactionStatus{State: StatusNotFound}, nil
+		case http.StatusGone:
+			return &TransactionStatus{State: StatusDropped, Reason: httpErr.Body}, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if string(body) == "Pending" {
+		return &TransactionStatus{State: StatusPending}, nil
+	}
+	status := TransactionStatus{}
+	err = json.Unmarshal(body, &status)
+	if err != nil {
+		return nil, err
+	}
+	status.State = StatusMined
+	return &status, nil
+}
+
+// GetPendingTransactions requests the IDs of the transactions waiting to be mined
 func (c *Client) GetPendingTransactions(ctx context.Context) ([]string, error) {
 	body, err := c.get(ctx, fmt.Sprintf("tx/pending"))
 	if err != nil {
@@ -142,22 +211,37 @@ func (c *Client) GetCurrentBlock(ctx context.Context) (*Block, error) {
 }
 
 // GetReward requests the current network reward
-func (c *Client) GetReward(ctx context.Context, data []byte) (string, error) {
+func (c *Client) GetReward(ctx context.Context, data []byte) (arweave.Amount, error) {
 	body, err := c.get(ctx, fmt.Sprintf("price/%d", len(data)))
 	if err != nil {
-		return "", err
+		return arweave.Amount{}, err
 	}
-	return string(body), nil
+	return arweave.ParseWinston(string(body))
+
+}
 
+// GetPrice requests the reward needed to store size bytes of data. If a target is
+// given, the price includes the fee for transferring to a wallet the network does
+// not know yet, if it is the case
+func (c *Client) GetPrice(ctx context.Context, size int64, target string) (arweave.Amount, error) {
+	endpoint := fmt.Sprintf("price/%d", size)
+	if target != "" {
+		endpoint = fmt.Sprintf("price/%d/%s", size, target)
+	}
+	body, err := c.get(ctx, endpoint)
+	if err != nil {
+		return arweave.Amount{}, err
+	}
+	return arweave.ParseWinston(string(body))
 }
 
 // GetBalance requests the current balance of an address
-func (c *Client) GetBalance(ctx context.Context, address string) (string, error) {
+func (c *Client) GetBalance(ctx context.Context, address string) (arweave.Amount, error) {
 	body, err := c.get(ctx, fmt.Sprintf("wallet/%s/balance", address))
 	if err != nil {
-		return "", err
+		return arweave.Amount{}, err
 	}
-	return string(body), nil
+	return arweave.ParseWinston(string(body))
 
 }
 
@@ -197,6 +281,17 @@ func (c *Client) Commit(ctx context.Context, data []byte) (string, error) {
 	return string(body), nil
 }
 
+// PostChunk uploads a chunk of the data of a format 2 transaction, whose header
+// must have been sent beforehand
+func (c *Client) PostChunk(ctx context.Context, chunk *tx.Chunk) error {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+	_, err = c.post(ctx, "chunk", data)
+	return err
+}
+
 func getResponse(resp io.ReadCloser, returnedError error) ([]byte, error) {
 	if resp != nil {
 		defer resp.Close()
@@ -212,17 +307,39 @@ func getResponse(resp io.ReadCloser, returnedError error) ([]byte, error) {
 	return b, nil
 }
 
+// HTTPError is returned when a node answers with a non 2xx status code
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%v %v", e.Status, e.Body)
+}
+
 func handleHTTPError(resp io.Reader, returnedError error) ([]byte, error) {
-	if resp != nil {
+	httpErr, ok := returnedError.(*HTTPError)
+	if resp != nil && ok {
 		buf := new(bytes.Buffer)
 		if _, err := buf.ReadFrom(resp); err == nil {
-			return nil, fmt.Errorf("%v %v", returnedError, buf.String())
+			httpErr.Body = buf.String()
 		}
 	}
 	return nil, returnedError
 }
 
-func (c *Client) requestWithContext(ctx context.Context, method string, url string, body []byte) (io.ReadCloser, error) {
+func (c *Client) requestWithContext(ctx context.Context, method string, endpoint string, body []byte) (io.ReadCloser, error) {
+	url := c.formatURL(endpoint)
+	ctx, span := c.tracer().Start(ctx, fmt.Sprintf("HTTP %s %s", method, endpointLabel(endpoint)),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.method", method),
+			attribute.String("http.url", url),
+		),
+	)
+	defer span.End()
+
 	req, err := http.NewRequest(method, url, ioutil.NopCloser(bytes.NewReader(body)))
 	if err != nil {
 		return nil, err
@@ -232,24 +349,65 @@ func (c *Client) requestWithContext(ctx context.Context, method string, url stri
 	if method == "POST" {
 		reqWithContext.Header.Set("Content-type", "application/json")
 	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(reqWithContext.Header))
 
-	resp, err := c.client.Do(req)
+	c.logger.Debug("sending request", "method", method, "url", url, "size", len(body))
+	start := time.Now()
+	resp, err := c.handler()(reqWithContext)
 	if err != nil {
+		c.logger.Warn("request failed", "method", method, "url", url, "duration", time.Since(start), "error", err)
+		c.observe(endpoint, 0, start, body)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
+	if resp.Body == nil {
+		resp.Body = http.NoBody
+	}
+	c.logger.Debug("received response", "method", method, "url", url, "status", resp.StatusCode, "duration", time.Since(start))
+	c.observe(endpoint, resp.StatusCode, start, body)
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return resp.Body, errors.New(resp.Status)
+		span.SetStatus(codes.Error, resp.Status)
+		return resp.Body, &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 	return resp.Body, nil
 }
 
+// observe reports a request to the metrics collector, if one is set
+func (c *Client) observe(endpoint string, status int, start time.Time, body []byte) {
+	if c.metrics == nil {
+		return
+	}
+	label := endpointLabel(endpoint)
+	c.metrics.ObserveRequest(c.url, label, status, time.Since(start))
+	if len(body) > 0 {
+		c.metrics.AddBytesUploaded(c.url, label, len(body))
+	}
+}
+
+// endpointLabel replaces the IDs, addresses and numbers of an endpoint by
+// placeholders, so that requests to the same endpoint are grouped together
+func endpointLabel(endpoint string) string {
+	segments := strings.Split(endpoint, "/")
+	for i, s := range segments {
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			segments[i] = "{n}"
+		} else if len(s) == 43 || len(s) == 64 {
+			// base64url encoding of 32 bytes IDs and addresses and of 48 bytes block hashes
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
 func (c *Client) post(ctx context.Context, endpoint string, body []byte) ([]byte, error) {
-	resp, err := c.requestWithContext(ctx, "POST", c.formatURL(endpoint), body)
+	resp, err := c.requestWithContext(ctx, "POST", endpoint, body)
 	return getResponse(resp, err)
 }
 
 func (c *Client) get(ctx context.Context, endpoint string) ([]byte, error) {
-	resp, err := c.requestWithContext(ctx, "GET", c.formatURL(endpoint), nil)
+	resp, err := c.requestWithContext(ctx, "GET", endpoint, nil)
 	return getResponse(resp, err)
 }
 
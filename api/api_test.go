@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+func TestMiddlewareOrder(t *testing.T) {
+	var received string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("X-Test")
+		w.Write([]byte("anchor"))
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := []string{}
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, "before "+name)
+				req.Header.Set("X-Test", req.Header.Get("X-Test")+name)
+				resp, err := next(req)
+				calls = append(calls, "after "+name)
+				return resp, err
+			}
+		}
+	}
+	c.Use(record("a"), record("b"))
+
+	anchor, err := c.TxAnchor(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "anchor", anchor)
+	assert.Equal(t, "ab", received, "middlewares did not modify the request in order")
+	assert.Equal(t, []string{"before a", "before b", "after b", "after a"}, calls)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	injected := errors.New("injected fault")
+	c.Use(func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			return nil, injected
+		}
+	})
+
+	_, err = c.TxAnchor(ctx)
+	assert.Equal(t, injected, err)
+	assert.False(t, hit, "request should not have reached the node")
+}
+
+func TestGetTransactionStatus(t *testing.T) {
+	cases := []struct {
+		code     int
+		body     string
+		expected *TransactionStatus
+	}{
+		{200, `{"block_height":10,"block_indep_hash":"hash","number_of_confirmations":3}`,
+			&TransactionStatus{State: StatusMined, BlockHeight: 10, BlockIndepHash: "hash", Confirmations: 3}},
+		{202, "Pending", &TransactionStatus{State: StatusPending}},
+		{404, "Not Found.", &TransactionStatus{State: StatusNotFound}},
+		{410, "tx_overspend", &TransactionStatus{State: StatusDropped, Reason: "tx_overspend"}},
+	}
+
+	for _, c := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/tx/id/status", r.URL.Path)
+			w.WriteHeader(c.code)
+			w.Write([]byte(c.body))
+		}))
+		cl, err := Dial(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		status, err := cl.GetTransactionStatus(ctx, "id")
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, c.expected, status)
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Invalid"))
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetBalance(ctx, "address")
+	httpErr, ok := err.(*HTTPError)
+	if !ok {
+		t.Fatalf("expected an *HTTPError, got %T", err)
+	}
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+	assert.Equal(t, "400 Bad Request Invalid", httpErr.Error())
+}
+
+// recordingLogger keeps the messages logged with their fields
+type recordingLogger struct {
+	messages []string
+	fields   []map[string]interface{}
+}
+
+func (l *recordingLogger) record(level string, msg string, args []interface{}) {
+	fields := map[string]interface{}{}
+	for i := 0; i+1 < len(args); i += 2 {
+		fields[args[i].(string)] = args[i+1]
+	}
+	l.messages = append(l.messages, level+" "+msg)
+	l.fields = append(l.fields, fields)
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) { l.record("DEBUG", msg, args) }
+func (l *recordingLogger) Info(msg string, args ...interface{})  { l.record("INFO", msg, args) }
+func (l *recordingLogger) Warn(msg string, args ...interface{})  { l.record("WARN", msg, args) }
+func (l *recordingLogger) Error(msg string, args ...interface{}) { l.record("ERROR", msg, args) }
+
+func TestLogger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("anchor"))
+	}))
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := &recordingLogger{}
+	c.SetLogger(logger)
+
+	_, err = c.TxAnchor(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DEBUG sending request", "DEBUG received response"}, logger.messages)
+	assert.Equal(t, "GET", logger.fields[0]["method"])
+	assert.Equal(t, srv.URL+"/tx_anchor", logger.fields[0]["url"])
+	assert.Equal(t, http.StatusOK, logger.fields[1]["status"])
+
+	srv.Close()
+	_, err = c.TxAnchor(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, "WARN request failed", logger.messages[3])
+	assert.Equal(t, err, logger.fields[3]["error"].(error), "the error is not the one returned")
+}
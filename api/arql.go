@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// ArQLExpression is a query expression of the legacy ArQL language, served on
+// the /arql endpoint of older gateways
+type ArQLExpression struct {
+	Op    string      `json:"op"`
+	Expr1 interface{} `json:"expr1"`
+	Expr2 interface{} `json:"expr2"`
+}
+
+// ArQLEquals matches the transactions with the tag set to the value. The "from"
+// and "to" names match the owner and the target of the transactions
+func ArQLEquals(name string, value string) *ArQLExpression {
+	return &ArQLExpression{Op: "equals", Expr1: name, Expr2: value}
+}
+
+// ArQLAnd matches the transactions matching all of the expressions
+func ArQLAnd(exprs ...*ArQLExpression) *ArQLExpression {
+	return combineArQL("and", exprs)
+}
+
+// ArQLOr matches the transactions matching any of the expressions
+func ArQLOr(exprs ...*ArQLExpression) *ArQLExpression {
+	return combineArQL("or", exprs)
+}
+
+// combineArQL chains the expressions with the binary operator
+func combineArQL(op string, exprs []*ArQLExpression) *ArQLExpression {
+	if len(exprs) == 0 {
+		return nil
+	}
+	expr := exprs[0]
+	for _, next := range exprs[1:] {
+		expr = &ArQLExpression{Op: op, Expr1: expr, Expr2: next}
+	}
+	return expr
+}
+
+// ArQL requests the IDs of the transactions matching the ArQL expression
+func (c *Client) ArQL(ctx context.Context, expr *ArQLExpression) ([]string, error) {
+	if expr == nil {
+		return nil, errors.New("empty arql expression")
+	}
+	query, err := json.Marshal(expr)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.post(ctx, "arql", query)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	err = json.Unmarshal(body, &ids)
+	if err != nil {
+		return nil, err
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	return ids, nil
+}
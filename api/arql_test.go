@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArQLExpressionJSON(t *testing.T) {
+	cases := []struct {
+		name     string
+		expr     *ArQLExpression
+		expected string
+	}{
+		{
+			"equals",
+			ArQLEquals("App-Name", "test"),
+			`{"op":"equals","expr1":"App-Name","expr2":"test"}`,
+		},
+		{
+			"and",
+			ArQLAnd(ArQLEquals("from", "a"), ArQLEquals("to", "b")),
+			`{"op":"and","expr1":{"op":"equals","expr1":"from","expr2":"a"},"expr2":{"op":"equals","expr1":"to","expr2":"b"}}`,
+		},
+		{
+			"or chained",
+			ArQLOr(ArQLEquals("a", "1"), ArQLEquals("b", "2"), ArQLEquals("c", "3")),
+			`{"op":"or","expr1":{"op":"or","expr1":{"op":"equals","expr1":"a","expr2":"1"},"expr2":{"op":"equals","expr1":"b","expr2":"2"}},"expr2":{"op":"equals","expr1":"c","expr2":"3"}}`,
+		},
+		{
+			"single",
+			ArQLAnd(ArQLEquals("a", "1")),
+			`{"op":"equals","expr1":"a","expr2":"1"}`,
+		},
+		{
+			"nested",
+			ArQLAnd(ArQLEquals("from", "a"), ArQLOr(ArQLEquals("to", "b"), ArQLEquals("to", "c"))),
+			`{"op":"and","expr1":{"op":"equals","expr1":"from","expr2":"a"},"expr2":{"op":"or","expr1":{"op":"equals","expr1":"to","expr2":"b"},"expr2":{"op":"equals","expr1":"to","expr2":"c"}}}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b, err := json.Marshal(c.expr)
+			assert.NoError(t, err)
+			assert.JSONEq(t, c.expected, string(b))
+		})
+	}
+	assert.Nil(t, ArQLOr())
+}
+
+func TestArQL(t *testing.T) {
+	response := `["tx-1","tx-2"]`
+	var received map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/arql", r.URL.Path)
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received = nil
+		json.Unmarshal(b, &received)
+		w.Write([]byte(response))
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := c.ArQL(ctx, ArQLAnd(ArQLEquals("from", "a"), ArQLEquals("App-Name", "test")))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"tx-1", "tx-2"}, ids)
+	assert.Equal(t, "and", received["op"])
+	assert.Equal(t, map[string]interface{}{"op": "equals", "expr1": "from", "expr2": "a"}, received["expr1"])
+
+	response = `null`
+	ids, err = c.ArQL(ctx, ArQLEquals("from", "nobody"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, ids)
+
+	_, err = c.ArQL(ctx, nil)
+	assert.Error(t, err)
+}
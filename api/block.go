@@ -0,0 +1,506 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// BlockVersion is the format of a block, which changed with the network's hard forks
+type BlockVersion int
+
+const (
+	// BlockVersion1 blocks inline the hash list and wallet list and use plain integers
+	BlockVersion1 BlockVersion = 1
+	// BlockVersion20 blocks (fork 2.0) introduced tx_root, the proof of access and
+	// the hash list merkle root, and encode large numbers as strings
+	BlockVersion20 BlockVersion = 20
+	// BlockVersion25 blocks (fork 2.5) added the USD to AR rates and the packing thresholds
+	BlockVersion25 BlockVersion = 25
+	// BlockVersion26 blocks (fork 2.6) are signed by the miner and carry the VDF
+	// (nonce limiter) information
+	BlockVersion26 BlockVersion = 26
+)
+
+func (v BlockVersion) String() string {
+	switch v {
+	case BlockVersion1:
+		return "1.x"
+	case BlockVersion20:
+		return "2.0"
+	case BlockVersion25:
+		return "2.5"
+	case BlockVersion26:
+		return "2.6"
+	}
+	return fmt.Sprintf("unknown(%d)", int(v))
+}
+
+// Block is an arweave block. Fields which were introduced or removed by a fork
+// are left empty when the block's version does not have them
+type Block struct {
+	Version BlockVersion
+
+	IndepHash      string
+	Hash           string
+	Nonce          string
+	PreviousBlock  string
+	Timestamp      int64
+	LastRetarget   int64
+	Height         int64
+	Diff           *big.Int
+	CumulativeDiff *big.Int
+	Txs            []string        // IDs of the transactions included in the block
+	RewardAddr     string          // Set to "unclaimed" when no address was given by the miner
+	Tags           json.RawMessage // Unused by the network, always empty
+	RewardPool     *big.Int
+	WeaveSize      *big.Int
+	BlockSize      *big.Int
+
+	// Version 1 only
+	HashList          []string
+	WalletListEntries []WalletEntry
+
+	// Version 2.0 onwards
+	TxRoot         string
+	WalletList     string // Root hash of the wallet list
+	HashListMerkle string
+	Poa            *ProofOfAccess
+
+	// Version 2.5 onwards
+	UsdToArRate              *Rate
+	ScheduledUsdToArRate     *Rate
+	Packing25Threshold       *big.Int
+	StrictDataSplitThreshold *big.Int
+
+	// Version 2.6 onwards
+	HashPreimage                  string
+	RecallByte                    *big.Int
+	RecallByte2                   *big.Int
+	Reward                        *big.Int
+	PreviousSolutionHash          string
+	PartitionNumber               int64
+	NonceLimiterInfo              *NonceLimiterInfo
+	Poa2                          *ProofOfAccess
+	Signature                     string
+	RewardKey                     string
+	PricePerGiBMinute             *big.Int
+	ScheduledPricePerGiBMinute    *big.Int
+	RewardHistoryHash             string
+	DebtSupply                    *big.Int
+	KryderPlusRateMultiplier      *big.Int
+	KryderPlusRateMultiplierLatch *big.Int
+	Denomination                  *big.Int
+	RedenominationHeight          int64
+	PreviousCumulativeDiff        *big.Int
+	DoubleSigningProof            json.RawMessage
+	MerkleRebaseSupportThreshold  *big.Int
+	ChunkHash                     string
+	Chunk2Hash                    string
+	BlockTimeHistoryHash          string
+}
+
+// WalletEntry is an entry of the wallet list inlined in version 1 blocks
+type WalletEntry struct {
+	Wallet   string   `json:"wallet"`
+	Quantity *big.Int `json:"quantity"`
+	LastTx   string   `json:"last_tx"`
+}
+
+// ProofOfAccess proves that the miner has access to a random chunk of the weave
+type ProofOfAccess struct {
+	Option   string `json:"option"`
+	TxPath   string `json:"tx_path"`
+	DataPath string `json:"data_path"`
+	Chunk    string `json:"chunk"`
+}
+
+// Rate is a fraction, used for the USD to AR exchange rate
+type Rate struct {
+	Dividend *big.Int
+	Divisor  *big.Int
+}
+
+// NonceLimiterInfo holds the verifiable delay function state of a 2.6 block
+type NonceLimiterInfo struct {
+	Output              string
+	GlobalStepNumber    int64
+	Seed                string
+	NextSeed            string
+	ZoneUpperBound      *big.Int
+	NextZoneUpperBound  *big.Int
+	PrevOutput          string
+	LastStepCheckpoints []string
+	Checkpoints         []string
+	VDFDifficulty       *big.Int // Only set from fork 2.7
+	NextVDFDifficulty   *big.Int // Only set from fork 2.7
+}
+
+type nonceLimiterInfoJSON struct {
+	Output              string   `json:"output"`
+	GlobalStepNumber    int64    `json:"global_step_number"`
+	Seed                string   `json:"seed"`
+	NextSeed            string   `json:"next_seed"`
+	ZoneUpperBound      *bigNum  `json:"zone_upper_bound"`
+	NextZoneUpperBound  *bigNum  `json:"next_zone_upper_bound"`
+	PrevOutput          string   `json:"prev_output"`
+	LastStepCheckpoints []string `json:"last_step_checkpoints"`
+	Checkpoints         []string `json:"checkpoints"`
+	VDFDifficulty       *bigNum  `json:"vdf_difficulty,omitempty"`
+	NextVDFDifficulty   *bigNum  `json:"next_vdf_difficulty,omitempty"`
+}
+
+// MarshalJSON marshals as JSON
+func (n *NonceLimiterInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(nonceLimiterInfoJSON{
+		Output:              n.Output,
+		GlobalStepNumber:    n.GlobalStepNumber,
+		Seed:                n.Seed,
+		NextSeed:            n.NextSeed,
+		ZoneUpperBound:      newBigNum(n.ZoneUpperBound),
+		NextZoneUpperBound:  newBigNum(n.NextZoneUpperBound),
+		PrevOutput:          n.PrevOutput,
+		LastStepCheckpoints: n.LastStepCheckpoints,
+		Checkpoints:         n.Checkpoints,
+		VDFDifficulty:       newBigNum(n.VDFDifficulty),
+		NextVDFDifficulty:   newBigNum(n.NextVDFDifficulty),
+	})
+}
+
+// UnmarshalJSON unmarshals as JSON
+func (n *NonceLimiterInfo) UnmarshalJSON(input []byte) error {
+	nj := nonceLimiterInfoJSON{}
+	err := json.Unmarshal(input, &nj)
+	if err != nil {
+		return err
+	}
+	*n = NonceLimiterInfo{
+		Output:              nj.Output,
+		GlobalStepNumber:    nj.GlobalStepNumber,
+		Seed:                nj.Seed,
+		NextSeed:            nj.NextSeed,
+		ZoneUpperBound:      nj.ZoneUpperBound.Int(),
+		NextZoneUpperBound:  nj.NextZoneUpperBound.Int(),
+		PrevOutput:          nj.PrevOutput,
+		LastStepCheckpoints: nj.LastStepCheckpoints,
+		Checkpoints:         nj.Checkpoints,
+		VDFDifficulty:       nj.VDFDifficulty.Int(),
+		NextVDFDifficulty:   nj.NextVDFDifficulty.Int(),
+	}
+	return nil
+}
+
+// blockJSON is the union of the fields of every block version, as sent by the nodes
+type blockJSON struct {
+	IndepHash      string          `json:"indep_hash"`
+	Hash           string          `json:"hash"`
+	Nonce          string          `json:"nonce"`
+	PreviousBlock  string          `json:"previous_block"`
+	Timestamp      int64           `json:"timestamp"`
+	LastRetarget   int64           `json:"last_retarget"`
+	Height         int64           `json:"height"`
+	Diff           *bigNum         `json:"diff"`
+	CumulativeDiff *bigNum         `json:"cumulative_diff,omitempty"`
+	Txs            []txRef         `json:"txs"`
+	RewardAddr     string          `json:"reward_addr"`
+	Tags           json.RawMessage `json:"tags"`
+	RewardPool     *bigNum         `json:"reward_pool"`
+	WeaveSize      *bigNum         `json:"weave_size"`
+	BlockSize      *bigNum         `json:"block_size"`
+	HashList       []string        `json:"hash_list,omitempty"`
+	WalletList     json.RawMessage `json:"wallet_list,omitempty"`
+	TxRoot         *string         `json:"tx_root,omitempty"`
+	HashListMerkle *string         `json:"hash_list_merkle,omitempty"`
+	Poa            *ProofOfAccess  `json:"poa,omitempty"`
+
+	UsdToArRate              []*bigNum `json:"usd_to_ar_rate,omitempty"`
+	ScheduledUsdToArRate     []*bigNum `json:"scheduled_usd_to_ar_rate,omitempty"`
+	Packing25Threshold       *bigNum   `json:"packing_2_5_threshold,omitempty"`
+	StrictDataSplitThreshold *bigNum   `json:"strict_data_split_threshold,omitempty"`
+
+	HashPreimage                  string            `json:"hash_preimage,omitempty"`
+	RecallByte                    *bigNum           `json:"recall_byte,omitempty"`
+	RecallByte2                   *bigNum           `json:"recall_byte2,omitempty"`
+	Reward                        *bigNum           `json:"reward,omitempty"`
+	PreviousSolutionHash          string            `json:"previous_solution_hash,omitempty"`
+	PartitionNumber               int64             `json:"partition_number,omitempty"`
+	NonceLimiterInfo              *NonceLimiterInfo `json:"nonce_limiter_info,omitempty"`
+	Poa2                          *ProofOfAccess    `json:"poa2,omitempty"`
+	Signature                     string            `json:"signature,omitempty"`
+	RewardKey                     string            `json:"reward_key,omitempty"`
+	PricePerGiBMinute             *bigNum           `json:"price_per_gib_minute,omitempty"`
+	ScheduledPricePerGiBMinute    *bigNum           `json:"scheduled_price_per_gib_minute,omitempty"`
+	RewardHistoryHash             string            `json:"reward_history_hash,omitempty"`
+	DebtSupply                    *bigNum           `json:"debt_supply,omitempty"`
+	KryderPlusRateMultiplier      *bigNum           `json:"kryder_plus_rate_multiplier,omitempty"`
+	KryderPlusRateMultiplierLatch *bigNum           `json:"kryder_plus_rate_multiplier_latch,omitempty"`
+	Denomination                  *bigNum           `json:"denomination,omitempty"`
+	RedenominationHeight          int64             `json:"redenomination_height,omitempty"`
+	PreviousCumulativeDiff        *bigNum           `json:"previous_cumulative_diff,omitempty"`
+	DoubleSigningProof            json.RawMessage   `json:"double_signing_proof,omitempty"`
+	MerkleRebaseSupportThreshold  *bigNum           `json:"merkle_rebase_support_threshold,omitempty"`
+	ChunkHash                     string            `json:"chunk_hash,omitempty"`
+	Chunk2Hash                    string            `json:"chunk2_hash,omitempty"`
+	BlockTimeHistoryHash          string            `json:"block_time_history_hash,omitempty"`
+}
+
+// walletEntryJSON is a version 1 wallet list entry, whose quantity is a plain number
+type walletEntryJSON struct {
+	Wallet   string  `json:"wallet"`
+	Quantity *bigNum `json:"quantity"`
+	LastTx   string  `json:"last_tx"`
+}
+
+// UnmarshalJSON decodes a block of any version
+func (b *Block) UnmarshalJSON(input []byte) error {
+	bj := blockJSON{}
+	err := json.Unmarshal(input, &bj)
+	if err != nil {
+		return err
+	}
+
+	*b = Block{
+		IndepHash:      bj.IndepHash,
+		Hash:           bj.Hash,
+		Nonce:          bj.Nonce,
+		PreviousBlock:  bj.PreviousBlock,
+		Timestamp:      bj.Timestamp,
+		LastRetarget:   bj.LastRetarget,
+		Height:         bj.Height,
+		Diff:           bj.Diff.Int(),
+		CumulativeDiff: bj.CumulativeDiff.Int(),
+		RewardAddr:     bj.RewardAddr,
+		Tags:           bj.Tags,
+		RewardPool:     bj.RewardPool.Int(),
+		WeaveSize:      bj.WeaveSize.Int(),
+		BlockSize:      bj.BlockSize.Int(),
+		HashList:       bj.HashList,
+		Poa:            bj.Poa,
+
+		Packing25Threshold:       bj.Packing25Threshold.Int(),
+		StrictDataSplitThreshold: bj.StrictDataSplitThreshold.Int(),
+
+		HashPreimage:                  bj.HashPreimage,
+		RecallByte:                    bj.RecallByte.Int(),
+		RecallByte2:                   bj.RecallByte2.Int(),
+		Reward:                        bj.Reward.Int(),
+		PreviousSolutionHash:          bj.PreviousSolutionHash,
+		PartitionNumber:               bj.PartitionNumber,
+		NonceLimiterInfo:              bj.NonceLimiterInfo,
+		Poa2:                          bj.Poa2,
+		Signature:                     bj.Signature,
+		RewardKey:                     bj.RewardKey,
+		PricePerGiBMinute:             bj.PricePerGiBMinute.Int(),
+		ScheduledPricePerGiBMinute:    bj.ScheduledPricePerGiBMinute.Int(),
+		RewardHistoryHash:             bj.RewardHistoryHash,
+		DebtSupply:                    bj.DebtSupply.Int(),
+		KryderPlusRateMultiplier:      bj.KryderPlusRateMultiplier.Int(),
+		KryderPlusRateMultiplierLatch: bj.KryderPlusRateMultiplierLatch.Int(),
+		Denomination:                  bj.Denomination.Int(),
+		RedenominationHeight:          bj.RedenominationHeight,
+		PreviousCumulativeDiff:        bj.PreviousCumulativeDiff.Int(),
+		DoubleSigningProof:            bj.DoubleSigningProof,
+		MerkleRebaseSupportThreshold:  bj.MerkleRebaseSupportThreshold.Int(),
+		ChunkHash:                     bj.ChunkHash,
+		Chunk2Hash:                    bj.Chunk2Hash,
+		BlockTimeHistoryHash:          bj.BlockTimeHistoryHash,
+	}
+
+	b.Txs = make([]string, 0, len(bj.Txs))
+	for _, ref := range bj.Txs {
+		b.Txs = append(b.Txs, string(ref))
+	}
+
+	// the wallet list is inlined in version 1 blocks, and replaced by its root hash afterwards
+	if len(bj.WalletList) > 0 && bj.WalletList[0] == '[' {
+		entries := []walletEntryJSON{}
+		err = json.Unmarshal(bj.WalletList, &entries)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			b.WalletListEntries = append(b.WalletListEntries, WalletEntry{
+				Wallet:   e.Wallet,
+				Quantity: e.Quantity.Int(),
+				LastTx:   e.LastTx,
+			})
+		}
+	} else if len(bj.WalletList) > 0 {
+		err = json.Unmarshal(bj.WalletList, &b.WalletList)
+		if err != nil {
+			return err
+		}
+	}
+
+	if bj.TxRoot != nil {
+		b.TxRoot = *bj.TxRoot
+	}
+	if bj.HashListMerkle != nil {
+		b.HashListMerkle = *bj.HashListMerkle
+	}
+	if b.UsdToArRate, err = decodeRate(bj.UsdToArRate); err != nil {
+		return err
+	}
+	if b.ScheduledUsdToArRate, err = decodeRate(bj.ScheduledUsdToArRate); err != nil {
+		return err
+	}
+
+	switch {
+	case bj.NonceLimiterInfo != nil:
+		b.Version = BlockVersion26
+	case bj.UsdToArRate != nil:
+		b.Version = BlockVersion25
+	case bj.TxRoot != nil || bj.HashListMerkle != nil:
+		b.Version = BlockVersion20
+	default:
+		b.Version = BlockVersion1
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the block the way the nodes serve it for its version
+func (b *Block) MarshalJSON() ([]byte, error) {
+	bj := blockJSON{
+		IndepHash:      b.IndepHash,
+		Hash:           b.Hash,
+		Nonce:          b.Nonce,
+		PreviousBlock:  b.PreviousBlock,
+		Timestamp:      b.Timestamp,
+		LastRetarget:   b.LastRetarget,
+		Height:         b.Height,
+		Diff:           newBigNum(b.Diff),
+		CumulativeDiff: newBigNum(b.CumulativeDiff),
+		Txs:            make([]txRef, 0, len(b.Txs)),
+		RewardAddr:     b.RewardAddr,
+		Tags:           b.Tags,
+		RewardPool:     newBigNum(b.RewardPool),
+		WeaveSize:      newBigNum(b.WeaveSize),
+		BlockSize:      newBigNum(b.BlockSize),
+		HashList:       b.HashList,
+		Poa:            b.Poa,
+
+		Packing25Threshold:       newBigNum(b.Packing25Threshold),
+		StrictDataSplitThreshold: newBigNum(b.StrictDataSplitThreshold),
+
+		HashPreimage:                  b.HashPreimage,
+		RecallByte:                    newBigNum(b.RecallByte),
+		RecallByte2:                   newBigNum(b.RecallByte2),
+		Reward:                        newBigNum(b.Reward),
+		PreviousSolutionHash:          b.PreviousSolutionHash,
+		PartitionNumber:               b.PartitionNumber,
+		NonceLimiterInfo:              b.NonceLimiterInfo,
+		Poa2:                          b.Poa2,
+		Signature:                     b.Signature,
+		RewardKey:                     b.RewardKey,
+		PricePerGiBMinute:             newBigNum(b.PricePerGiBMinute),
+		ScheduledPricePerGiBMinute:    newBigNum(b.ScheduledPricePerGiBMinute),
+		RewardHistoryHash:             b.RewardHistoryHash,
+		DebtSupply:                    newBigNum(b.DebtSupply),
+		KryderPlusRateMultiplier:      newBigNum(b.KryderPlusRateMultiplier),
+		KryderPlusRateMultiplierLatch: newBigNum(b.KryderPlusRateMultiplierLatch),
+		Denomination:                  newBigNum(b.Denomination),
+		RedenominationHeight:          b.RedenominationHeight,
+		PreviousCumulativeDiff:        newBigNum(b.PreviousCumulativeDiff),
+		DoubleSigningProof:            b.DoubleSigningProof,
+		MerkleRebaseSupportThreshold:  newBigNum(b.MerkleRebaseSupportThreshold),
+		ChunkHash:                     b.ChunkHash,
+		Chunk2Hash:                    b.Chunk2Hash,
+		BlockTimeHistoryHash:          b.BlockTimeHistoryHash,
+	}
+	for _, id := range b.Txs {
+		bj.Txs = append(bj.Txs, txRef(id))
+	}
+
+	var err error
+	if b.Version == BlockVersion1 {
+		entries := make([]walletEntryJSON, 0, len(b.WalletListEntries))
+		for _, e := range b.WalletListEntries {
+			entries = append(entries, walletEntryJSON{Wallet: e.Wallet, Quantity: newBigNum(e.Quantity), LastTx: e.LastTx})
+		}
+		bj.WalletList, err = json.Marshal(entries)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(bj)
+	}
+
+	bj.WalletList, err = json.Marshal(b.WalletList)
+	if err != nil {
+		return nil, err
+	}
+	bj.TxRoot = &b.TxRoot
+	bj.HashListMerkle = &b.HashListMerkle
+	if b.UsdToArRate != nil {
+		bj.UsdToArRate = []*bigNum{newBigNum(b.UsdToArRate.Dividend), newBigNum(b.UsdToArRate.Divisor)}
+	}
+	if b.ScheduledUsdToArRate != nil {
+		bj.ScheduledUsdToArRate = []*bigNum{newBigNum(b.ScheduledUsdToArRate.Dividend), newBigNum(b.ScheduledUsdToArRate.Divisor)}
+	}
+	return json.Marshal(bj)
+}
+
+func decodeRate(rate []*bigNum) (*Rate, error) {
+	if rate == nil {
+		return nil, nil
+	}
+	if len(rate) != 2 {
+		return nil, fmt.Errorf("rate should have 2 elements, got %d", len(rate))
+	}
+	return &Rate{Dividend: rate[0].Int(), Divisor: rate[1].Int()}, nil
+}
+
+// txRef is a transaction reference in a block. Nodes send transaction IDs, but
+// some version 1 blocks embed whole transactions, in which case we keep their ID
+type txRef string
+
+func (r *txRef) UnmarshalJSON(input []byte) error {
+	if len(input) > 0 && input[0] == '{' {
+		embedded := struct {
+			ID string `json:"id"`
+		}{}
+		err := json.Unmarshal(input, &embedded)
+		if err != nil {
+			return err
+		}
+		*r = txRef(embedded.ID)
+		return nil
+	}
+	var id string
+	err := json.Unmarshal(input, &id)
+	if err != nil {
+		return err
+	}
+	*r = txRef(id)
+	return nil
+}
+
+// bigNum decodes an integer sent either as a JSON number or as a string, and
+// encodes it as a string
+type bigNum big.Int
+
+func newBigNum(i *big.Int) *bigNum {
+	return (*bigNum)(i)
+}
+
+// Int returns the *big.Int value, nil if the field was absent
+func (n *bigNum) Int() *big.Int {
+	return (*big.Int)(n)
+}
+
+func (n *bigNum) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*big.Int)(n).String())
+}
+
+func (n *bigNum) UnmarshalJSON(input []byte) error {
+	s := string(input)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	_, ok := (*big.Int)(n).SetString(s, 10)
+	if !ok {
+		return errors.New("invalid integer " + string(input))
+	}
+	return nil
+}
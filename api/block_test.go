@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func helperLoadBytes(t *testing.T, name string) []byte {
+	path := filepath.Join("testdata", name) // relative path
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes
+}
+
+func bigFromString(t *testing.T, s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		t.Fatalf("invalid integer %s", s)
+	}
+	return n
+}
+
+// The fixtures follow the fields of each block version. Their values are not
+// those of mainnet blocks, but differ between versions so that a field decoded
+// from the wrong place shows
+func TestDecodeBlockVersions(t *testing.T) {
+	cases := []struct {
+		file      string
+		version   BlockVersion
+		height    int64
+		diff      string
+		txs       int
+		weaveSize string
+		txRoot    string
+	}{
+		{"block_v1.json", BlockVersion1, 1000, "24", 2, "0", ""},
+		{"block_v20.json", BlockVersion20, 422250, "115792089039110416381777338180143427138542839347564543096838393017186453241856", 2, "22989189536274", "lTBDFUEKbiTb35pERGmmeEsiKSXjqKKt5QQOCefbK7M"},
+		{"block_v25.json", BlockVersion25, 812970, "115792089204934016935492484612598447418349296148296880339553436540917616885760", 0, "21255492670006", ""},
+		{"block_v26.json", BlockVersion26, 1132210, "115792089237316195423570985008687907853269984665640564039457584007913129639935", 1, "63312198812318", "4xOdFNIdlkz3ubf4Jj5KZ3mHnJ5lHnmV8LaPipc_XRo"},
+	}
+
+	for _, c := range cases {
+		b := Block{}
+		err := json.Unmarshal(helperLoadBytes(t, c.file), &b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, c.version, b.Version, "%s: version does not match", c.file)
+		assert.Equal(t, c.height, b.Height, "%s: height does not match", c.file)
+		assert.Equal(t, bigFromString(t, c.diff), b.Diff, "%s: diff does not match", c.file)
+		assert.Len(t, b.Txs, c.txs, "%s: txs do not match", c.file)
+		assert.Equal(t, bigFromString(t, c.weaveSize), b.WeaveSize, "%s: weave size does not match", c.file)
+		assert.Equal(t, c.txRoot, b.TxRoot, "%s: tx root does not match", c.file)
+
+		// encoding and decoding again should give us back the same block
+		encoded, err := json.Marshal(&b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := Block{}
+		err = json.Unmarshal(encoded, &decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, b, decoded, "%s: block changed after a round trip", c.file)
+	}
+}
+
+func TestDecodeBlockV1(t *testing.T) {
+	b := Block{}
+	err := json.Unmarshal(helperLoadBytes(t, "block_v1.json"), &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "TXEMBEDDEDID", b.Txs[1], "embedded transaction id was not extracted")
+	assert.Len(t, b.HashList, 2)
+	assert.Len(t, b.WalletListEntries, 2)
+	assert.Equal(t, bigFromString(t, "2500000000000000000000"), b.WalletListEntries[1].Quantity)
+	assert.Equal(t, "", b.TxRoot)
+	assert.Nil(t, b.Poa)
+}
+
+func TestDecodeBlockV26(t *testing.T) {
+	b := Block{}
+	err := json.Unmarshal(helperLoadBytes(t, "block_v26.json"), &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, big.NewInt(7), b.UsdToArRate.Dividend)
+	assert.Equal(t, big.NewInt(42), b.UsdToArRate.Divisor)
+	assert.Equal(t, bigFromString(t, "63312198812318"), b.WeaveSize)
+	assert.Equal(t, bigFromString(t, "1029386512"), b.Reward)
+	assert.Equal(t, int64(9815), b.NonceLimiterInfo.GlobalStepNumber)
+	assert.Equal(t, bigFromString(t, "139856384573440"), b.NonceLimiterInfo.ZoneUpperBound)
+	assert.Nil(t, b.RecallByte2, "absent fields should stay nil")
+}
+
+func TestGetBlockByHeight(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != fmt.Sprintf("/block/height/%d", 422250) {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(helperLoadBytes(t, "block_v20.json"))
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.GetBlockByHeight(ctx, 422250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, BlockVersion20, b.Version)
+	assert.Equal(t, "2", b.Poa.Option)
+	assert.Equal(t, bigFromString(t, "3274117151420928"), b.CumulativeDiff)
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dev43/arweave-go"
+)
+
+// The following endpoints only exist on development nodes such as arlocal
+
+// Mint credits a wallet with amount out of thin air, returning its new balance
+func (c *Client) Mint(ctx context.Context, address string, amount arweave.Amount) (arweave.Amount, error) {
+	body, err := c.get(ctx, fmt.Sprintf("mint/%s/%s", address, amount.String()))
+	if err != nil {
+		return arweave.Amount{}, err
+	}
+	return arweave.ParseWinston(string(body))
+}
+
+// Mine mines blocks, including the pending transactions in the first one
+func (c *Client) Mine(ctx context.Context, blocks int) error {
+	_, err := c.get(ctx, fmt.Sprintf("mine/%d", blocks))
+	return err
+}
+
+// Reset wipes the node's blocks, transactions and wallets
+func (c *Client) Reset(ctx context.Context) error {
+	_, err := c.get(ctx, "reset")
+	return err
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/tx"
+)
+
+// GraphQLError is an error returned by the gateway for a graphql query
+type GraphQLError struct {
+	Messages []string
+}
+
+func (e *GraphQLError) Error() string {
+	return "graphql: " + strings.Join(e.Messages, "; ")
+}
+
+// GraphQL sends a query to the gateway's graphql endpoint and decodes the data
+// of the response into out
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := c.post(ctx, "graphql", body)
+	if err != nil {
+		return err
+	}
+	result := struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{}
+	err = json.Unmarshal(resp, &result)
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		gqlErr := &GraphQLError{}
+		for _, e := range result.Errors {
+			gqlErr.Messages = append(gqlErr.Messages, e.Message)
+		}
+		return gqlErr
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+// SortOrder is the order in which transactions are returned
+type SortOrder string
+
+const (
+	// HeightDesc returns the most recent transactions first, the gateway's default
+	HeightDesc SortOrder = "HEIGHT_DESC"
+	// HeightAsc returns the oldest transactions first
+	HeightAsc SortOrder = "HEIGHT_ASC"
+)
+
+// TagFilter matches transactions having a tag with one of the values. With
+// Exclude set, it matches the ones that do not
+type TagFilter struct {
+	Name    string
+	Values  []string
+	Exclude bool
+}
+
+// TransactionQuery filters the transactions returned by the gateway. The
+// filters are combined with a logical and
+type TransactionQuery struct {
+	ids        []string
+	owners     []string
+	recipients []string
+	tags       []TagFilter
+	minHeight  *int64
+	maxHeight  *int64
+	bundledIn  []string
+	pageSize   int
+	after      string
+	sort       SortOrder
+}
+
+// NewTransactionQuery creates a query matching every transaction
+func NewTransactionQuery() *TransactionQuery {
+	return &TransactionQuery{}
+}
+
+// IDs restricts the query to the transactions with the given IDs
+func (q *TransactionQuery) IDs(ids ...string) *TransactionQuery {
+	q.ids = append(q.ids, ids...)
+	return q
+}
+
+// Owners restricts the query to transactions sent by one of the addresses
+func (q *TransactionQuery) Owners(addresses ...string) *TransactionQuery {
+	q.owners = append(q.owners, addresses...)
+	return q
+}
+
+// Recipients restricts the query to transactions sent to one of the addresses
+func (q *TransactionQuery) Recipients(addresses ...string) *TransactionQuery {
+	q.recipients = append(q.recipients, addresses...)
+	return q
+}
+
+// Tag restricts the query to transactions with the tag set to one of the values
+func (q *TransactionQuery) Tag(name string, values ...string) *TransactionQuery {
+	q.tags = append(q.tags, TagFilter{Name: name, Values: values})
+	return q
+}
+
+// Tags adds tag filters to the query
+func (q *TransactionQuery) Tags(filters ...TagFilter) *TransactionQuery {
+	q.tags = append(q.tags, filters...)
+	return q
+}
+
+// MinHeight restricts the query to transactions mined at or after the height
+func (q *TransactionQuery) MinHeight(height int64) *TransactionQuery {
+	q.minHeight = &height
+	return q
+}
+
+// MaxHeight restricts the query to transactions mined at or before the height
+func (q *TransactionQuery) MaxHeight(height int64) *TransactionQuery {
+	q.maxHeight = &height
+	return q
+}
+
+// BlockRange restricts the query to transactions mined between the two heights, inclusive
+func (q *TransactionQuery) BlockRange(min int64, max int64) *TransactionQuery {
+	return q.MinHeight(min).MaxHeight(max)
+}
+
+// BundledIn restricts the query to data items bundled in one of the transactions
+func (q *TransactionQuery) BundledIn(ids ...string) *TransactionQuery {
+	q.bundledIn = append(q.bundledIn, ids...)
+	return q
+}
+
+// PageSize sets the number of transactions requested at once
+func (q *TransactionQuery) PageSize(size int) *TransactionQuery {
+	q.pageSize = size
+	return q
+}
+
+// After starts the query after the transaction with the given cursor
+func (q *TransactionQuery) After(cursor string) *TransactionQuery {
+	q.after = cursor
+	return q
+}
+
+// Sort sets the order of the transactions
+func (q *TransactionQuery) Sort(order SortOrder) *TransactionQuery {
+	q.sort = order
+	return q
+}
+
+const transactionsQuery = `query($ids: [ID!], $owners: [String!], $recipients: [String!], $tags: [TagFilter!], $block: BlockFilter, $bundledIn: [ID!], $first: Int, $after: String, $sort: SortOrder) {
+  transactions(ids: $ids, owners: $owners, recipients: $recipients, tags: $tags, block: $block, bundledIn: $bundledIn, first: $first, after: $after, sort: $sort) {
+    pageInfo { hasNextPage }
+    edges {
+      cursor
+      node {
+        id
+        anchor
+        signature
+        recipient
+        owner { address key }
+        fee { winston ar }
+        quantity { winston ar }
+        data { size type }
+        tags { name value }
+        block { id timestamp height previous }
+        bundledIn { id }
+      }
+    }
+  }
+}`
+
+// variables returns the graphql variables of the query, leaving out unset filters
+func (q *TransactionQuery) variables(after string) map[string]interface{} {
+	vars := map[string]interface{}{}
+	if len(q.ids) > 0 {
+		vars["ids"] = q.ids
+	}
+	if len(q.owners) > 0 {
+		vars["owners"] = q.owners
+	}
+	if len(q.recipients) > 0 {
+		vars["recipients"] = q.recipients
+	}
+	if len(q.tags) > 0 {
+		tags := []map[string]interface{}{}
+		for _, t := range q.tags {
+			op := "EQ"
+			if t.Exclude {
+				op = "NEQ"
+			}
+			tags = append(tags, map[string]interface{}{"name": t.Name, "values": t.Values, "op": op})
+		}
+		vars["tags"] = tags
+	}
+	if q.minHeight != nil || q.maxHeight != nil {
+		block := map[string]interface{}{}
+		if q.minHeight != nil {
+			block["min"] = *q.minHeight
+		}
+		if q.maxHeight != nil {
+			block["max"] = *q.maxHeight
+		}
+		vars["block"] = block
+	}
+	if len(q.bundledIn) > 0 {
+		vars["bundledIn"] = q.bundledIn
+	}
+	if q.pageSize > 0 {
+		vars["first"] = q.pageSize
+	}
+	if after != "" {
+		vars["after"] = after
+	}
+	if q.sort != "" {
+		vars["sort"] = q.sort
+	}
+	return vars
+}
+
+// GraphQLTransaction is a transaction as returned by the gateway's graphql endpoint
+type GraphQLTransaction struct {
+	ID        string `json:"id"`
+	Anchor    string `json:"anchor"`
+	Signature string `json:"signature"`
+	Recipient string `json:"recipient"`
+	Owner     struct {
+		Address string `json:"address"`
+		Key     string `json:"key"`
+	} `json:"owner"`
+	Fee      GraphQLAmount `json:"fee"`
+	Quantity GraphQLAmount `json:"quantity"`
+	Data     struct {
+		Size string `json:"size"`
+		Type string `json:"type"`
+	} `json:"data"`
+	// Tags are in plain text
+	Tags      []tx.Tag       `json:"tags"`
+	Block     *GraphQLBlock  `json:"block"`
+	BundledIn *GraphQLBundle `json:"bundledIn"`
+}
+
+// GraphQLAmount is an amount as returned by the gateway, both in winston and in AR
+type GraphQLAmount struct {
+	Winston arweave.Amount `json:"winston"`
+	AR      string         `json:"ar"`
+}
+
+// GraphQLBlock is the block a transaction was mined in, nil while it is pending
+type GraphQLBlock struct {
+	ID        string `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+	Height    int64  `json:"height"`
+	Previous  string `json:"previous"`
+}
+
+// GraphQLBundle is the bundle a data item is part of
+type GraphQLBundle struct {
+	ID string `json:"id"`
+}
+
+type transactionsPage struct {
+	Transactions struct {
+		PageInfo struct {
+			HasNextPage bool `json:"hasNextPage"`
+		} `json:"pageInfo"`
+		Edges []struct {
+			Cursor string             `json:"cursor"`
+			Node   GraphQLTransaction `json:"node"`
+		} `json:"edges"`
+	} `json:"transactions"`
+}
+
+// TransactionIterator walks through the transactions matching a query, fetching
+// the pages as needed
+type TransactionIterator struct {
+	c       *Client
+	ctx     context.Context
+	query   *TransactionQuery
+	page    transactionsPage
+	index   int
+	cursor  string
+	hasNext bool
+	err     error
+}
+
+// QueryTransactions returns an iterator over the transactions matching the query
+//
+//	it := c.QueryTransactions(ctx, api.NewTransactionQuery().Owners(address))
+//	for it.Next() {
+//		txn := it.Transaction()
+//	}
+//	if it.Err() != nil {
+//		//...
+//	}
+func (c *Client) QueryTransactions(ctx context.Context, q *TransactionQuery) *TransactionIterator {
+	return &TransactionIterator{
+		c:       c,
+		ctx:     ctx,
+		query:   q,
+		index:   -1,
+		cursor:  q.after,
+		hasNext: true,
+	}
+}
+
+// Next advances to the next transaction, requesting the next page if needed. It
+// returns false once there are no more transactions or an error occurred
+func (it *TransactionIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.index++
+	if it.index < len(it.page.Transactions.Edges) {
+		it.cursor = it.page.Transactions.Edges[it.index].Cursor
+		return true
+	}
+	if !it.hasNext {
+		return false
+	}
+	page := transactionsPage{}
+	it.err = it.c.GraphQL(it.ctx, transactionsQuery, it.query.variables(it.cursor), &page)
+	if it.err != nil {
+		return false
+	}
+	it.page = page
+	it.index = 0
+	it.hasNext = page.Transactions.PageInfo.HasNextPage
+	if len(page.Transactions.Edges) == 0 {
+		return false
+	}
+	it.cursor = page.Transactions.Edges[0].Cursor
+	return true
+}
+
+// Transaction returns the current transaction
+func (it *TransactionIterator) Transaction() *GraphQLTransaction {
+	return &it.page.Transactions.Edges[it.index].Node
+}
+
+// Cursor returns the cursor of the current transaction, which can be given to
+// TransactionQuery.After to resume iterating from there
+func (it *TransactionIterator) Cursor() string {
+	return it.cursor
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
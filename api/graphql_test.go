@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryTransactions(t *testing.T) {
+	requests := []map[string]interface{}{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/graphql", r.URL.Path)
+		req := struct {
+			Variables map[string]interface{} `json:"variables"`
+		}{}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, req.Variables)
+
+		// serves 3 transactions, 2 per page
+		start := 0
+		if req.Variables["after"] != nil {
+			fmt.Sscanf(req.Variables["after"].(string), "cursor-%d", &start)
+		}
+		edges := []map[string]interface{}{}
+		for i := start + 1; i <= 3 && i <= start+2; i++ {
+			edges = append(edges, map[string]interface{}{
+				"cursor": fmt.Sprintf("cursor-%d", i),
+				"node": map[string]interface{}{
+					"id":    fmt.Sprintf("tx-%d", i),
+					"tags":  []map[string]string{{"name": "App-Name", "value": "test"}},
+					"block": map[string]interface{}{"height": 100 + i},
+				},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"transactions": map[string]interface{}{
+					"pageInfo": map[string]bool{"hasNextPage": start+2 < 3},
+					"edges":    edges,
+				},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := NewTransactionQuery().
+		Owners("owner").
+		Tag("App-Name", "test").
+		BlockRange(100, 200).
+		PageSize(2)
+	it := c.QueryTransactions(ctx, q)
+	ids := []string{}
+	for it.Next() {
+		ids = append(ids, it.Transaction().ID)
+		assert.Equal(t, "test", it.Transaction().Tags[0].Value)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"tx-1", "tx-2", "tx-3"}, ids)
+	assert.Equal(t, "cursor-3", it.Cursor())
+
+	assert.Len(t, requests, 2, "expected one request per page")
+	assert.Nil(t, requests[0]["after"])
+	assert.Equal(t, "cursor-2", requests[1]["after"])
+	assert.Equal(t, []interface{}{"owner"}, requests[0]["owners"])
+	assert.Equal(t, map[string]interface{}{"min": 100.0, "max": 200.0}, requests[0]["block"])
+	assert.Equal(t, 2.0, requests[0]["first"])
+	assert.Nil(t, requests[0]["recipients"], "unset filters should not be sent")
+}
+
+func TestGraphQLError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":[{"message":"bad query"}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := c.QueryTransactions(ctx, NewTransactionQuery())
+	assert.False(t, it.Next())
+	assert.Equal(t, &GraphQLError{Messages: []string{"bad query"}}, it.Err())
+}
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/tx"
+)
+
+// historyPageSize is the number of transactions requested per graphql page, the
+// maximum allowed by the gateways
+const historyPageSize = 100
+
+// Direction tells whether a transaction was sent or received by a wallet
+type Direction string
+
+const (
+	// DirectionOut transactions were signed by the wallet
+	DirectionOut Direction = "out"
+	// DirectionIn transactions were sent to the wallet by another one
+	DirectionIn Direction = "in"
+	// DirectionSelf transactions were sent by the wallet to itself
+	DirectionSelf Direction = "self"
+)
+
+// HistoryRecord is a transaction of the history of a wallet
+type HistoryRecord struct {
+	TxID      string
+	Direction Direction
+	From      string
+	To        string // Empty for data transactions
+	Quantity  arweave.Amount
+	Fee       arweave.Amount
+	DataSize  int64
+	Tags      []tx.Tag // In plain text
+	Pending   bool     // The block fields are not set for pending transactions
+	Height    int64
+	BlockID   string
+	Timestamp int64 // Not known when walking the last_tx chain
+}
+
+// HistoryIterator walks through the transactions of a wallet, from the most
+// recent to the oldest, pending ones first
+type HistoryIterator struct {
+	c       *Client
+	ctx     context.Context
+	address string
+	started bool
+
+	// graphql: the sent and received transactions, merged in order of height
+	sent         *TransactionIterator
+	received     *TransactionIterator
+	sentHead     *GraphQLTransaction
+	receivedHead *GraphQLTransaction
+
+	// fallback: the chain of the wallet's last_tx
+	fallback bool
+	nextTx   string
+
+	record *HistoryRecord
+	err    error
+}
+
+// WalletHistory returns an iterator over the transactions sent from or to the
+// address, using the gateway's graphql endpoint. If the node does not serve
+// graphql, answering 404 or 405, it falls back to walking the chain of the
+// wallet's last_tx, which only reaches the transactions sent by the wallet up
+// to the first one anchored to a block rather than to the previous transaction.
+// The transactions received by the wallet are never reported in fallback mode,
+// see Fallback
+//
+//	it := c.WalletHistory(ctx, address)
+//	for it.Next() {
+//		record := it.Record()
+//	}
+//	if it.Err() != nil {
+//		//...
+//	}
+func (c *Client) WalletHistory(ctx context.Context, address string) *HistoryIterator {
+	query := func() *TransactionQuery {
+		return NewTransactionQuery().Sort(HeightDesc).PageSize(historyPageSize)
+	}
+	return &HistoryIterator{
+		c:        c,
+		ctx:      ctx,
+		address:  address,
+		sent:     c.QueryTransactions(ctx, query().Owners(address)),
+		received: c.QueryTransactions(ctx, query().Recipients(address)),
+	}
+}
+
+// Next advances to the next transaction. It returns false once there are no
+// more transactions or an error occurred
+func (it *HistoryIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		it.err = it.start()
+		if it.err != nil {
+			return false
+		}
+	}
+	if it.fallback {
+		return it.nextInChain()
+	}
+
+	var t *GraphQLTransaction
+	switch {
+	case it.sentHead == nil && it.receivedHead == nil:
+		return false
+	case it.receivedHead == nil || (it.sentHead != nil && before(it.sentHead, it.receivedHead)):
+		t = it.sentHead
+		it.sentHead, it.err = it.pull(it.sent)
+	default:
+		t = it.receivedHead
+		it.receivedHead, it.err = it.pull(it.received)
+	}
+	if it.err != nil {
+		return false
+	}
+	it.record = it.fromGraphQL(t)
+	return true
+}
+
+// Record returns the current transaction
+func (it *HistoryIterator) Record() *HistoryRecord {
+	return it.record
+}
+
+// Fallback returns true if the history comes from the last_tx chain, in which
+// case it lacks the transactions received by the wallet
+func (it *HistoryIterator) Fallback() bool {
+	return it.fallback
+}
+
+// Err returns the error which stopped the iteration, if any
+func (it *HistoryIterator) Err() error {
+	return it.err
+}
+
+// start requests the first transaction of both graphql queries, or the wallet's
+// last_tx if the node has no graphql endpoint
+func (it *HistoryIterator) start() error {
+	var err error
+	it.sentHead, err = it.pull(it.sent)
+	if httpErr, ok := err.(*HTTPError); ok &&
+		(httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusMethodNotAllowed) {
+		it.fallback = true
+		it.nextTx, err = it.c.LastTransaction(it.ctx, it.address)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	it.receivedHead, err = it.pull(it.received)
+	return err
+}
+
+// pull returns the next transaction of a query, nil once there are no more.
+// Transactions sent by the wallet to itself are only taken from the sent ones
+func (it *HistoryIterator) pull(ti *TransactionIterator) (*GraphQLTransaction, error) {
+	for ti.Next() {
+		t := *ti.Transaction()
+		if ti == it.received && t.Owner.Address == it.address {
+			continue
+		}
+		return &t, nil
+	}
+	return nil, ti.Err()
+}
+
+// nextInChain fetches the transaction the previous one was anchored to
+func (it *HistoryIterator) nextInChain() bool {
+	if it.nextTx == "" {
+		return false
+	}
+	t, err := it.c.GetTransaction(it.ctx, it.nextTx)
+	if httpErr, ok := err.(*HTTPError); ok && httpErr.StatusCode == http.StatusNotFound {
+		// the anchor is a block hash, the chain stops there
+		return false
+	}
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if t == nil || t.OwnerAddress() != it.address {
+		return false
+	}
+	status, err := it.c.GetTransactionStatus(it.ctx, it.nextTx)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	tags, err := t.Tags()
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.record = &HistoryRecord{
+		TxID:      it.nextTx,
+		Direction: DirectionOut,
+		From:      it.address,
+		To:        t.Target(),
+		Quantity:  t.Quantity(),
+		Fee:       t.Reward(),
+		DataSize:  t.DataSize(),
+		Tags:      tags,
+		Pending:   status.State != StatusMined,
+		Height:    status.BlockHeight,
+		BlockID:   status.BlockIndepHash,
+	}
+	if t.Target() == it.address {
+		it.record.Direction = DirectionSelf
+	}
+	it.nextTx = t.LastTx()
+	return true
+}
+
+func (it *HistoryIterator) fromGraphQL(t *GraphQLTransaction) *HistoryRecord {
+	r := &HistoryRecord{
+		TxID:      t.ID,
+		Direction: DirectionIn,
+		From:      t.Owner.Address,
+		To:        t.Recipient,
+		Quantity:  t.Quantity.Winston,
+		Fee:       t.Fee.Winston,
+		Tags:      t.Tags,
+		Pending:   t.Block == nil,
+	}
+	switch {
+	case r.From == it.address && r.To == it.address:
+		r.Direction = DirectionSelf
+	case r.From == it.address:
+		r.Direction = DirectionOut
+	}
+	r.DataSize, _ = strconv.ParseInt(t.Data.Size, 10, 64)
+	if t.Block != nil {
+		r.Height = t.Block.Height
+		r.BlockID = t.Block.ID
+		r.Timestamp = t.Block.Timestamp
+	}
+	return r
+}
+
+// before returns true if a comes before b in the history: pending transactions
+// first, then by descending height
+func before(a *GraphQLTransaction, b *GraphQLTransaction) bool {
+	if a.Block == nil {
+		return true
+	}
+	if b.Block == nil {
+		return false
+	}
+	return a.Block.Height >= b.Block.Height
+}
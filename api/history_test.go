@@ -0,0 +1,176 @@
+package api
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/tx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletHistory(t *testing.T) {
+	const me, other = "me", "other"
+	node := func(id string, owner string, recipient string, height int64) map[string]interface{} {
+		n := map[string]interface{}{
+			"id":        id,
+			"recipient": recipient,
+			"owner":     map[string]string{"address": owner},
+			"quantity":  map[string]string{"winston": "10"},
+			"fee":       map[string]string{"winston": "1"},
+			"data":      map[string]string{"size": "42"},
+		}
+		if height > 0 {
+			n["block"] = map[string]interface{}{"id": "block", "height": height, "timestamp": 1600000000}
+		}
+		return n
+	}
+	// sorted by descending height, pending first
+	nodes := []map[string]interface{}{
+		node("pending", me, other, 0),
+		node("in-12", other, me, 12),
+		node("self-11", me, me, 11),
+		node("out-10", me, "", 10),
+		node("in-9", other, me, 9),
+		node("unrelated", other, other, 8),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := struct {
+			Variables struct {
+				Owners     []string `json:"owners"`
+				Recipients []string `json:"recipients"`
+				Sort       string   `json:"sort"`
+			} `json:"variables"`
+		}{}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, string(HeightDesc), req.Variables.Sort)
+		edges := []map[string]interface{}{}
+		for _, n := range nodes {
+			owner := n["owner"].(map[string]string)["address"]
+			if (len(req.Variables.Owners) > 0 && owner == req.Variables.Owners[0]) ||
+				(len(req.Variables.Recipients) > 0 && n["recipient"] == req.Variables.Recipients[0]) {
+				edges = append(edges, map[string]interface{}{"cursor": n["id"], "node": n})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"transactions": map[string]interface{}{
+					"pageInfo": map[string]bool{"hasNextPage": false},
+					"edges":    edges,
+				},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := c.WalletHistory(ctx, me)
+	records := []*HistoryRecord{}
+	for it.Next() {
+		records = append(records, it.Record())
+	}
+	assert.NoError(t, it.Err())
+	assert.False(t, it.Fallback())
+	ids := []string{}
+	directions := []Direction{}
+	for _, r := range records {
+		ids = append(ids, r.TxID)
+		directions = append(directions, r.Direction)
+	}
+	assert.Equal(t, []string{"pending", "in-12", "self-11", "out-10", "in-9"}, ids)
+	assert.Equal(t, []Direction{DirectionOut, DirectionIn, DirectionSelf, DirectionOut, DirectionIn}, directions)
+	assert.True(t, records[0].Pending)
+	assert.False(t, records[1].Pending)
+	assert.Equal(t, int64(12), records[1].Height)
+	assert.Equal(t, int64(1600000000), records[1].Timestamp)
+	assert.Equal(t, "10", records[1].Quantity.String())
+	assert.Equal(t, "1", records[1].Fee.String())
+	assert.Equal(t, int64(42), records[1].DataSize)
+}
+
+func TestWalletHistoryFallback(t *testing.T) {
+	owner := big.NewInt(65537)
+	older := tx.NewTransaction("block-hash", owner, arweave.NewWinston(5), "other", nil, arweave.NewWinston(1))
+	newer := tx.NewTransaction("older", owner, arweave.NewWinston(0), "", []byte("data"), arweave.NewWinston(2))
+	address := newer.OwnerAddress()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wallet/"+address+"/last_tx", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("newer"))
+	})
+	for id, txn := range map[string]*tx.Transaction{"newer": newer, "older": older} {
+		txn := txn
+		mux.HandleFunc("/tx/"+id, func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(txn)
+		})
+	}
+	mux.HandleFunc("/tx/newer/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"block_height":20,"block_indep_hash":"block-20","number_of_confirmations":1}`))
+	})
+	mux.HandleFunc("/tx/older/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"block_height":10,"block_indep_hash":"block-10","number_of_confirmations":11}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it := c.WalletHistory(ctx, address)
+	records := []*HistoryRecord{}
+	for it.Next() {
+		records = append(records, it.Record())
+	}
+	assert.NoError(t, it.Err())
+	assert.True(t, it.Fallback())
+	if assert.Len(t, records, 2, "the walk should stop at the block hash anchor") {
+		assert.Equal(t, "newer", records[0].TxID)
+		assert.Equal(t, DirectionOut, records[0].Direction)
+		assert.Equal(t, int64(4), records[0].DataSize)
+		assert.Equal(t, "2", records[0].Fee.String())
+		assert.Equal(t, "older", records[1].TxID)
+		assert.Equal(t, "other", records[1].To)
+		assert.Equal(t, "5", records[1].Quantity.String())
+		assert.Equal(t, int64(10), records[1].Height)
+		assert.Equal(t, "block-10", records[1].BlockID)
+		assert.False(t, records[1].Pending)
+	}
+}
+
+func TestWalletHistoryGraphQLErrors(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway} {
+		lastTx := false
+		mux := http.NewServeMux()
+		mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+		mux.HandleFunc("/wallet/address/last_tx", func(w http.ResponseWriter, r *http.Request) {
+			lastTx = true
+		})
+		srv := httptest.NewServer(mux)
+
+		c, err := Dial(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		it := c.WalletHistory(ctx, "address")
+		assert.False(t, it.Next())
+		httpErr, ok := it.Err().(*HTTPError)
+		if assert.True(t, ok, "expected an *HTTPError, got %v", it.Err()) {
+			assert.Equal(t, status, httpErr.StatusCode)
+		}
+		assert.False(t, it.Fallback(), "a %d does not fall back", status)
+		assert.False(t, lastTx)
+		srv.Close()
+	}
+}
@@ -0,0 +1,26 @@
+package api
+
+import "net/http"
+
+// Handler sends a request to an arweave node and returns its response
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler. It can act on the request before calling next
+// (logging, signing, fault injection...) and on the response once next returns
+type Middleware func(next Handler) Handler
+
+// Use appends middlewares to the client's request chain. Middlewares are run in
+// the order they were added, the first one being the outermost. Use is not safe
+// to call concurrently with requests and should be called right after Dial
+func (c *Client) Use(middlewares ...Middleware) {
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// handler builds the middleware chain around the underlying http client
+func (c *Client) handler() Handler {
+	h := Handler(c.client.Do)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	return h
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer used by the client
+const instrumentationName = "github.com/Dev43/arweave-go/api"
+
+// SetTracerProvider sets the OpenTelemetry tracer provider used to create a span
+// for every request. The global provider is used if none is set
+func (c *Client) SetTracerProvider(tp trace.TracerProvider) {
+	c.tracerProvider = tp
+}
+
+func (c *Client) tracer() trace.Tracer {
+	if c.tracerProvider == nil {
+		return otel.GetTracerProvider().Tracer(instrumentationName)
+	}
+	return c.tracerProvider.Tracer(instrumentationName)
+}
@@ -13,28 +13,32 @@ type NetworkInfo struct {
 	NodeStateLatency int    `json:"node_state_latency"`
 }
 
-// Block struct
-type Block struct {
-	HashList      []string      `json:"hash_list"`
-	Nonce         string        `json:"nonce"`
-	PreviousBlock string        `json:"previous_block"`
-	Timestamp     int           `json:"timestamp"`
-	LastRetarget  int           `json:"last_retarget"`
-	Diff          int           `json:"diff"`
-	Height        int           `json:"height"`
-	Hash          string        `json:"hash"`
-	IndepHash     string        `json:"indep_hash"`
-	Txs           []interface{} `json:"txs"`
-	WalletList    []struct {
-		Wallet   string `json:"wallet"`
-		Quantity int64  `json:"quantity"`
-		LastTx   string `json:"last_tx"`
-	} `json:"wallet_list"`
-	RewardAddr string        `json:"reward_addr"`
-	Tags       []interface{} `json:"tags"`
-	RewardPool int           `json:"reward_pool"`
-	WeaveSize  int           `json:"weave_size"`
-	BlockSize  int           `json:"block_size"`
+// TransactionState is the state of a transaction on the node
+type TransactionState string
+
+const (
+	// StatusPending transactions are waiting in the mempool
+	StatusPending TransactionState = "pending"
+	// StatusMined transactions are included in a block
+	StatusMined TransactionState = "mined"
+	// StatusNotFound transactions are unknown to the node
+	StatusNotFound TransactionState = "not_found"
+	// StatusDropped transactions were rejected or evicted by the node
+	StatusDropped TransactionState = "dropped"
+)
+
+// TransactionStatus struct
+type TransactionStatus struct {
+	State          TransactionState `json:"-"`
+	BlockHeight    int64            `json:"block_height"`
+	BlockIndepHash string           `json:"block_indep_hash"`
+	Confirmations  int64            `json:"number_of_confirmations"`
+	Reason         string           `json:"-"` // Why a dropped transaction was rejected, as given by the node
+}
+
+// Confirmed returns true if the transaction is mined with at least n confirmations
+func (s *TransactionStatus) Confirmed(n int64) bool {
+	return s.State == StatusMined && s.Confirmations >= n
 }
 
 var allowedFields = map[string]bool{
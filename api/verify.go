@@ -0,0 +1,310 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/Dev43/arweave-go/tx"
+	"github.com/Dev43/arweave-go/utils"
+)
+
+// Heights of the mainnet forks which changed the way blocks are hashed
+const (
+	forkHeight20 = 422250
+	forkHeight24 = 633720
+	forkHeight25 = 812970
+	forkHeight26 = 1132210
+)
+
+// ErrUnsupportedBlockVersion is returned when a hash cannot be recomputed for the
+// block's version. Only the historical blocks from fork 2.0 up to fork 2.6 are
+// supported, which excludes every block mined on the current network
+var ErrUnsupportedBlockVersion = errors.New("unsupported block version")
+
+// VerificationError is returned when a recomputed block hash does not match the block's field
+type VerificationError struct {
+	Field    string
+	Expected string
+	Computed string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("block %s mismatch: expected %s, computed %s", e.Field, e.Expected, e.Computed)
+}
+
+// ComputeIndepHash recomputes the independent hash of a block from its fields.
+// Only historical blocks are supported, from fork 2.0 (height 422250) up to fork
+// 2.6 (height 1132210). From 2.6 blocks are signed and commit to the VDF state
+// (nonce_limiter_info) and a second proof of access, which this package does not
+// hash: ErrUnsupportedBlockVersion is returned for them, as for the blocks of the
+// first versions
+func (b *Block) ComputeIndepHash() ([]byte, error) {
+	if !b.verifiable() {
+		return nil, ErrUnsupportedBlockVersion
+	}
+	bds, err := b.dataSegment()
+	if err != nil {
+		return nil, err
+	}
+	hash, err := utils.DecodeString(b.Hash)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := utils.DecodeString(b.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	list := []interface{}{bds, hash, nonce}
+	// from fork 2.4 the proof of access is hashed here instead of in the data segment
+	if b.Height >= forkHeight24 {
+		poa, err := b.poaList()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, poa)
+	}
+	return tx.DeepHash(list)
+}
+
+// ComputeTxRoot recomputes the tx_root of a block from its transactions. All the
+// transactions listed in the block must be given, in any order. Blocks before
+// fork 2.0 have no tx_root
+func (b *Block) ComputeTxRoot(txs []*tx.Transaction) ([]byte, error) {
+	if b.Height < forkHeight20 {
+		return nil, ErrUnsupportedBlockVersion
+	}
+	byID := map[string]*tx.Transaction{}
+	for _, t := range txs {
+		byID[t.Hash()] = t
+	}
+	ordered := make([]*tx.Transaction, 0, len(b.Txs))
+	for _, id := range b.Txs {
+		t, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("missing transaction %s", id)
+		}
+		ordered = append(ordered, t)
+	}
+	// nodes build the tree over the transactions sorted by format and ID
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Format() != ordered[j].Format() {
+			return ordered[i].Format() < ordered[j].Format()
+		}
+		return bytes.Compare(ordered[i].ID(), ordered[j].ID()) < 0
+	})
+
+	leaves := []tx.MerkleLeaf{}
+	offset := int64(0)
+	for _, t := range ordered {
+		offset += t.DataSize()
+		leaves = append(leaves, tx.MerkleLeaf{Data: t.ComputeDataRoot(), Offset: offset})
+		// from fork 2.5 the data of each transaction is padded to a chunk boundary,
+		// the padding being committed to with an empty data root
+		if b.Height >= forkHeight25 && t.DataSize()%tx.MaxChunkSize != 0 {
+			offset += tx.MaxChunkSize - t.DataSize()%tx.MaxChunkSize
+			leaves = append(leaves, tx.MerkleLeaf{Data: []byte{}, Offset: offset})
+		}
+	}
+	if len(leaves) == 0 {
+		return []byte{}, nil
+	}
+	return tx.MerkleRoot(leaves), nil
+}
+
+// VerifyIndepHash checks that the block's independent hash matches its fields
+func (b *Block) VerifyIndepHash() error {
+	computed, err := b.ComputeIndepHash()
+	if err != nil {
+		return err
+	}
+	return compareHash("indep_hash", b.IndepHash, computed)
+}
+
+// VerifyTxRoot checks that the block's tx_root matches the given transactions
+func (b *Block) VerifyTxRoot(txs []*tx.Transaction) error {
+	computed, err := b.ComputeTxRoot(txs)
+	if err != nil {
+		return err
+	}
+	return compareHash("tx_root", b.TxRoot, computed)
+}
+
+// Verify checks both the independent hash and the tx_root of the block, which
+// must be a historical block as described in ComputeIndepHash
+func (b *Block) Verify(txs []*tx.Transaction) error {
+	if !b.verifiable() {
+		return ErrUnsupportedBlockVersion
+	}
+	err := b.VerifyTxRoot(txs)
+	if err != nil {
+		return err
+	}
+	return b.VerifyIndepHash()
+}
+
+// VerifyBlock requests a block and all of its transactions, and verifies the
+// block's independent hash and tx_root against them. Only historical blocks can
+// be verified, ErrUnsupportedBlockVersion is returned with the block otherwise,
+// before its transactions are requested
+func (c *Client) VerifyBlock(ctx context.Context, blockID string) (*Block, error) {
+	b, err := c.GetBlockByID(ctx, blockID)
+	if err != nil {
+		return nil, err
+	}
+	if !b.verifiable() {
+		return b, ErrUnsupportedBlockVersion
+	}
+	txs := make([]*tx.Transaction, 0, len(b.Txs))
+	for _, id := range b.Txs {
+		t, err := c.GetTransaction(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			return nil, fmt.Errorf("transaction %s is pending", id)
+		}
+		// nodes do not always inline large format 1 data, which we need to derive its data root
+		if t.Format() == 1 && t.DataSize() > 0 && len(t.RawData()) == 0 {
+			data, err := c.GetData(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			raw, err := utils.DecodeString(data)
+			if err != nil {
+				return nil, err
+			}
+			t.SetData(raw)
+		}
+		txs = append(txs, t)
+	}
+	return b, b.Verify(txs)
+}
+
+// verifiable tells whether the independent hash of the block can be recomputed
+func (b *Block) verifiable() bool {
+	return b.Height >= forkHeight20 && b.Height < forkHeight26
+}
+
+// dataSegment computes the block data segment, the deep hash of the block fields
+// which are not part of the proof of work
+func (b *Block) dataSegment() ([]byte, error) {
+	base, err := b.dataSegmentBase()
+	if err != nil {
+		return nil, err
+	}
+	walletList, err := utils.DecodeString(b.WalletList)
+	if err != nil {
+		return nil, err
+	}
+	hashListMerkle, err := utils.DecodeString(b.HashListMerkle)
+	if err != nil {
+		return nil, err
+	}
+	return tx.DeepHash([]interface{}{
+		base,
+		strconv.FormatInt(b.Timestamp, 10),
+		strconv.FormatInt(b.LastRetarget, 10),
+		bigString(b.Diff),
+		bigString(b.CumulativeDiff),
+		bigString(b.RewardPool),
+		walletList,
+		hashListMerkle,
+	})
+}
+
+func (b *Block) dataSegmentBase() ([]byte, error) {
+	if len(b.Tags) > 0 && string(b.Tags) != "[]" {
+		return nil, errors.New("cannot hash a block with tags")
+	}
+	previousBlock, err := utils.DecodeString(b.PreviousBlock)
+	if err != nil {
+		return nil, err
+	}
+	txRoot, err := utils.DecodeString(b.TxRoot)
+	if err != nil {
+		return nil, err
+	}
+	txIDs := make([]interface{}, 0, len(b.Txs))
+	for _, id := range b.Txs {
+		raw, err := utils.DecodeString(id)
+		if err != nil {
+			return nil, err
+		}
+		txIDs = append(txIDs, raw)
+	}
+	rewardAddr := []byte(b.RewardAddr)
+	if b.RewardAddr != "unclaimed" {
+		rewardAddr, err = utils.DecodeString(b.RewardAddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	props := []interface{}{}
+	if b.Height >= forkHeight25 {
+		if b.UsdToArRate == nil || b.ScheduledUsdToArRate == nil {
+			return nil, errors.New("missing usd to ar rates")
+		}
+		props = append(props,
+			bigString(b.UsdToArRate.Dividend),
+			bigString(b.UsdToArRate.Divisor),
+			bigString(b.ScheduledUsdToArRate.Dividend),
+			bigString(b.ScheduledUsdToArRate.Divisor),
+			bigString(b.Packing25Threshold),
+			bigString(b.StrictDataSplitThreshold),
+		)
+	}
+	props = append(props,
+		strconv.FormatInt(b.Height, 10),
+		previousBlock,
+		txRoot,
+		txIDs,
+		bigString(b.BlockSize),
+		bigString(b.WeaveSize),
+		rewardAddr,
+		[]interface{}{},
+	)
+	if b.Height < forkHeight24 {
+		poa, err := b.poaList()
+		if err != nil {
+			return nil, err
+		}
+		props = append(props, poa)
+	}
+	return tx.DeepHash(props)
+}
+
+func (b *Block) poaList() ([]interface{}, error) {
+	if b.Poa == nil {
+		return nil, errors.New("missing proof of access")
+	}
+	list := []interface{}{b.Poa.Option}
+	for _, field := range []string{b.Poa.TxPath, b.Poa.DataPath, b.Poa.Chunk} {
+		raw, err := utils.DecodeString(field)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, raw)
+	}
+	return list, nil
+}
+
+func compareHash(field string, expected string, computed []byte) error {
+	if utils.EncodeToBase64(computed) != expected {
+		return &VerificationError{Field: field, Expected: expected, Computed: utils.EncodeToBase64(computed)}
+	}
+	return nil
+}
+
+// bigString formats a number the way nodes do when hashing it, absent numbers being 0
+func bigString(n *big.Int) string {
+	if n == nil {
+		return "0"
+	}
+	return n.String()
+}
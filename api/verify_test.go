@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Dev43/arweave-go/tx"
+	"github.com/Dev43/arweave-go/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// helperBlockWithTxs loads a block fixture and replaces its transactions with a
+// format 1 and a format 2 transaction, whose JSON is returned by ID
+func helperBlockWithTxs(t *testing.T, name string) (*Block, map[string][]byte) {
+	b := &Block{}
+	err := json.Unmarshal(helperLoadBytes(t, name), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	format2ID := utils.EncodeToBase64([]byte(strings.Repeat("b", 32)))
+	format1ID := utils.EncodeToBase64([]byte(strings.Repeat("a", 32)))
+	txs := map[string][]byte{
+		format2ID: []byte(`{"format":2,"id":"` + format2ID + `","last_tx":"","owner":"AQAB","tags":[],"target":"","quantity":"0",` +
+			`"data":"","data_size":"300000","data_root":"` + utils.EncodeToBase64(tx.GenerateDataRoot(make([]byte, 300000))) + `","reward":"1","signature":""}`),
+		format1ID: []byte(`{"id":"` + format1ID + `","last_tx":"","owner":"AQAB","tags":[],"target":"","quantity":"0",` +
+			`"data":"` + utils.EncodeToBase64([]byte("hello")) + `","reward":"1","signature":""}`),
+	}
+	b.Txs = []string{format2ID, format1ID}
+	return b, txs
+}
+
+func helperDecodeTxs(t *testing.T, raw map[string][]byte) []*tx.Transaction {
+	txs := []*tx.Transaction{}
+	for _, r := range raw {
+		txn := &tx.Transaction{}
+		err := json.Unmarshal(r, txn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, txn)
+	}
+	return txs
+}
+
+// The fixtures are not mainnet blocks, their hashes are computed here: the tests
+// check that every field and transaction is committed to
+func TestVerifyBlock(t *testing.T) {
+	for _, name := range []string{"block_v20.json", "block_v25.json"} {
+		b, raw := helperBlockWithTxs(t, name)
+		txs := helperDecodeTxs(t, raw)
+
+		txRoot, err := b.ComputeTxRoot(txs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.TxRoot = utils.EncodeToBase64(txRoot)
+		indepHash, err := b.ComputeIndepHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.IndepHash = utils.EncodeToBase64(indepHash)
+		assert.NoError(t, b.Verify(txs), name)
+
+		// any change to the block's fields changes its independent hash
+		b.Timestamp++
+		assert.IsType(t, &VerificationError{}, b.VerifyIndepHash(), name)
+		b.Timestamp--
+
+		// as does any change to its transactions
+		for _, txn := range txs {
+			if txn.Format() == 1 {
+				txn.SetData([]byte("world"))
+			}
+		}
+		assert.IsType(t, &VerificationError{}, b.VerifyTxRoot(txs), name)
+		_, err = b.ComputeTxRoot(txs[:1])
+		assert.Error(t, err, "%s: a missing transaction should not verify", name)
+	}
+}
+
+func TestVerifyBlockPadding(t *testing.T) {
+	b, raw := helperBlockWithTxs(t, "block_v20.json")
+	txs := helperDecodeTxs(t, raw)
+	rootV20, err := b.ComputeTxRoot(txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// from fork 2.5 transaction data is padded to chunk boundaries
+	b.Height = forkHeight25
+	rootV25, err := b.ComputeTxRoot(txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, rootV20, rootV25)
+}
+
+func TestVerifyUnsupportedVersions(t *testing.T) {
+	for _, name := range []string{"block_v1.json", "block_v26.json"} {
+		b := &Block{}
+		err := json.Unmarshal(helperLoadBytes(t, name), b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = b.ComputeIndepHash()
+		assert.Equal(t, ErrUnsupportedBlockVersion, err, name)
+		assert.Equal(t, ErrUnsupportedBlockVersion, b.Verify(nil), name)
+	}
+}
+
+func TestClientVerifyBlockUnsupported(t *testing.T) {
+	requested := []string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		w.Write(helperLoadBytes(t, "block_v26.json"))
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.VerifyBlock(ctx, "block")
+	assert.Equal(t, ErrUnsupportedBlockVersion, err)
+	assert.Equal(t, int64(forkHeight26), b.Height)
+	assert.Equal(t, []string{"/block/hash/block"}, requested, "the transactions should not be requested")
+}
+
+func TestClientVerifyBlock(t *testing.T) {
+	b, raw := helperBlockWithTxs(t, "block_v25.json")
+	txs := helperDecodeTxs(t, raw)
+	txRoot, err := b.ComputeTxRoot(txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.TxRoot = utils.EncodeToBase64(txRoot)
+	indepHash, err := b.ComputeIndepHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.IndepHash = utils.EncodeToBase64(indepHash)
+	encoded, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/block/hash/"+b.IndepHash {
+			w.Write(encoded)
+			return
+		}
+		for id, txn := range raw {
+			if r.URL.Path == "/tx/"+id {
+				w.Write(txn)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.VerifyBlock(ctx, b.IndepHash)
+	assert.NoError(t, err)
+}
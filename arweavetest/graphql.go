@@ -0,0 +1,234 @@
+package arweavetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Dev43/arweave-go/tx"
+)
+
+// graphqlRequest is a transactions query, as sent by api.Client.QueryTransactions.
+// The filters are read from the variables, the selection of fields is ignored and
+// every field is returned
+type graphqlRequest struct {
+	Query     string `json:"query"`
+	Variables struct {
+		IDs        []string `json:"ids"`
+		Owners     []string `json:"owners"`
+		Recipients []string `json:"recipients"`
+		Tags       []struct {
+			Name   string   `json:"name"`
+			Values []string `json:"values"`
+			Op     string   `json:"op"`
+		} `json:"tags"`
+		Block *struct {
+			Min *int64 `json:"min"`
+			Max *int64 `json:"max"`
+		} `json:"block"`
+		BundledIn []string `json:"bundledIn"`
+		First     int      `json:"first"`
+		After     string   `json:"after"`
+		Sort      string   `json:"sort"`
+	} `json:"variables"`
+}
+
+type graphqlAmount struct {
+	Winston string `json:"winston"`
+	AR      string `json:"ar"`
+}
+
+type graphqlNode struct {
+	ID        string `json:"id"`
+	Anchor    string `json:"anchor"`
+	Signature string `json:"signature"`
+	Recipient string `json:"recipient"`
+	Owner     struct {
+		Address string `json:"address"`
+		Key     string `json:"key"`
+	} `json:"owner"`
+	Fee      graphqlAmount `json:"fee"`
+	Quantity graphqlAmount `json:"quantity"`
+	Data     struct {
+		Size string `json:"size"`
+		Type string `json:"type"`
+	} `json:"data"`
+	Tags      []tx.Tag      `json:"tags"`
+	Block     *graphqlBlock `json:"block"`
+	BundledIn interface{}   `json:"bundledIn"`
+}
+
+type graphqlBlock struct {
+	ID        string `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+	Height    int64  `json:"height"`
+	Previous  string `json:"previous"`
+}
+
+type graphqlEdge struct {
+	Cursor string      `json:"cursor"`
+	Node   graphqlNode `json:"node"`
+}
+
+type graphqlError struct {
+	Message string `json:"message"`
+}
+
+// graphql answers transactions queries. The cursors are the transaction IDs
+func (n *Node) graphql(body []byte) (int, interface{}) {
+	req := graphqlRequest{}
+	err := json.Unmarshal(body, &req)
+	if err != nil {
+		return http.StatusBadRequest, "Invalid JSON."
+	}
+	if !strings.Contains(req.Query, "transactions") {
+		return http.StatusOK, map[string]interface{}{
+			"errors": []graphqlError{{Message: "only transactions queries are supported"}},
+		}
+	}
+	vars := req.Variables
+
+	matches := []*record{}
+	for _, id := range n.order {
+		r := n.txs[id]
+		if r.dropped == "" && n.matchQuery(r, &req) {
+			matches = append(matches, r)
+		}
+	}
+	// transactions are received in order, so sorting by height only requires
+	// moving the pending ones, which have no height yet
+	mined, pending := []*record{}, []*record{}
+	for _, r := range matches {
+		if r.height < 0 {
+			pending = append(pending, r)
+		} else {
+			mined = append(mined, r)
+		}
+	}
+	if vars.Sort == "HEIGHT_ASC" {
+		matches = append(mined, pending...)
+	} else {
+		matches = pending
+		for i := len(mined) - 1; i >= 0; i-- {
+			matches = append(matches, mined[i])
+		}
+	}
+
+	if vars.After != "" {
+		for i, r := range matches {
+			if r.txn.Hash() == vars.After {
+				matches = matches[i+1:]
+				break
+			}
+		}
+	}
+	first := vars.First
+	if first <= 0 {
+		first = 10
+	}
+	if first > 100 {
+		first = 100
+	}
+	hasNext := len(matches) > first
+	if hasNext {
+		matches = matches[:first]
+	}
+
+	edges := []graphqlEdge{}
+	for _, r := range matches {
+		edges = append(edges, graphqlEdge{Cursor: r.txn.Hash(), Node: n.graphqlNode(r)})
+	}
+	return http.StatusOK, map[string]interface{}{
+		"data": map[string]interface{}{
+			"transactions": map[string]interface{}{
+				"pageInfo": map[string]bool{"hasNextPage": hasNext},
+				"edges":    edges,
+			},
+		},
+	}
+}
+
+func (n *Node) matchQuery(r *record, req *graphqlRequest) bool {
+	vars := req.Variables
+	t := r.txn
+	if len(vars.IDs) > 0 && !contains(vars.IDs, t.Hash()) {
+		return false
+	}
+	if len(vars.Owners) > 0 && !contains(vars.Owners, t.OwnerAddress()) {
+		return false
+	}
+	if len(vars.Recipients) > 0 && !contains(vars.Recipients, t.Target()) {
+		return false
+	}
+	// no transaction is bundled on this node
+	if len(vars.BundledIn) > 0 {
+		return false
+	}
+	if vars.Block != nil {
+		if r.height < 0 {
+			return false
+		}
+		if vars.Block.Min != nil && r.height < *vars.Block.Min {
+			return false
+		}
+		if vars.Block.Max != nil && r.height > *vars.Block.Max {
+			return false
+		}
+	}
+	tags, err := t.Tags()
+	if err != nil {
+		return false
+	}
+	for _, filter := range vars.Tags {
+		found := false
+		for _, tag := range tags {
+			if tag.Name == filter.Name && contains(filter.Values, tag.Value) {
+				found = true
+				break
+			}
+		}
+		if found == (filter.Op == "NEQ") {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *Node) graphqlNode(r *record) graphqlNode {
+	t := r.txn
+	node := graphqlNode{
+		ID:        t.Hash(),
+		Anchor:    t.LastTx(),
+		Signature: t.Signature(),
+		Recipient: t.Target(),
+		Fee:       graphqlAmount{Winston: t.Reward().String(), AR: t.Reward().AR()},
+		Quantity:  graphqlAmount{Winston: t.Quantity().String(), AR: t.Quantity().AR()},
+	}
+	node.Owner.Address = t.OwnerAddress()
+	node.Owner.Key = t.Owner()
+	node.Data.Size = strconv.FormatInt(t.DataSize(), 10)
+	node.Tags, _ = t.Tags()
+	if node.Tags == nil {
+		node.Tags = []tx.Tag{}
+	}
+	for _, tag := range node.Tags {
+		if tag.Name == "Content-Type" {
+			node.Data.Type = tag.Value
+		}
+	}
+	if r.height >= 0 {
+		b := n.blocks[r.height]
+		node.Block = &graphqlBlock{ID: b.IndepHash, Timestamp: b.Timestamp, Height: b.Height, Previous: b.PreviousBlock}
+	}
+	return node
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,379 @@
+package arweavetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/tx"
+	"github.com/Dev43/arweave-go/utils"
+)
+
+// serveHTTP applies the faults before routing the request
+func (n *Node) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	n.requests = append(n.requests, r.Method+" "+r.URL.Path)
+	n.mu.Unlock()
+
+	if f := n.takeFault(r); f != nil {
+		if f.Delay > 0 {
+			select {
+			case <-time.After(f.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if f.Drop {
+			if hj, ok := w.(http.Hijacker); ok {
+				conn, _, err := hj.Hijack()
+				if err == nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+		if f.Status != 0 {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(f.Status)
+			w.Write([]byte(f.Body))
+			return
+		}
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	status, resp := n.route(r.Method, strings.Split(strings.Trim(r.URL.Path, "/"), "/"), body)
+	switch v := resp.(type) {
+	case string:
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(status)
+		w.Write([]byte(v))
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(b)
+	}
+}
+
+// route handles a request, returning its status code and either a string or a
+// value encoded as JSON
+func (n *Node) route(method string, path []string, body []byte) (int, interface{}) {
+	switch {
+	case method == "GET" && match(path, "info"):
+		return http.StatusOK, n.info()
+	case method == "GET" && match(path, "peers"):
+		return http.StatusOK, []string{}
+	case method == "GET" && match(path, "tx_anchor"):
+		return http.StatusOK, n.current().IndepHash
+	case method == "GET" && match(path, "price", "*"):
+		return n.handlePrice(path[1], "")
+	case method == "GET" && match(path, "price", "*", "*"):
+		return n.handlePrice(path[1], path[2])
+	case method == "POST" && match(path, "tx"):
+		return n.postTransaction(body)
+	case method == "GET" && match(path, "tx", "pending"):
+		return http.StatusOK, append([]string{}, n.pending...)
+	case method == "GET" && match(path, "tx", "*"):
+		return n.getTransaction(path[1])
+	case method == "GET" && match(path, "unconfirmed_tx", "*"):
+		r, ok := n.txs[path[1]]
+		if !ok || r.dropped != "" {
+			return http.StatusNotFound, "Not Found."
+		}
+		return http.StatusOK, r.txn
+	case method == "GET" && match(path, "tx", "*", "status"):
+		return n.getStatus(path[1])
+	case method == "GET" && match(path, "tx", "*", "offset"):
+		return n.getOffset(path[1])
+	case method == "GET" && match(path, "tx", "*", "data"):
+		return n.getData(path[1])
+	case method == "GET" && match(path, "wallet", "*", "balance"):
+		return http.StatusOK, n.balances[path[1]].String()
+	case method == "GET" && match(path, "wallet", "*", "last_tx"):
+		return http.StatusOK, n.lastTxs[path[1]]
+	case method == "POST" && match(path, "chunk"):
+		return n.postChunk(body)
+	case method == "GET" && match(path, "chunk", "*"):
+		return n.getChunk(path[1])
+	case method == "GET" && match(path, "current_block"):
+		return http.StatusOK, n.current()
+	case method == "GET" && match(path, "block", "hash", "*"):
+		for _, b := range n.blocks {
+			if b.IndepHash == path[2] {
+				return http.StatusOK, b
+			}
+		}
+		return http.StatusNotFound, "Block not found."
+	case method == "GET" && match(path, "block", "height", "*"):
+		height, err := strconv.ParseInt(path[2], 10, 64)
+		if err != nil || height < 0 || height > n.height() {
+			return http.StatusNotFound, "Block not found."
+		}
+		return http.StatusOK, n.blocks[height]
+	case method == "POST" && match(path, "graphql"):
+		return n.graphql(body)
+	// development endpoints, as served by arlocal
+	case method == "GET" && match(path, "mint", "*", "*"):
+		amount, err := arweave.ParseWinston(path[2])
+		if err != nil || amount.Sign() < 0 {
+			return http.StatusBadRequest, "Invalid amount."
+		}
+		n.balances[path[1]] = n.balances[path[1]].Add(amount)
+		return http.StatusOK, n.balances[path[1]].String()
+	case method == "GET" && (match(path, "mine") || match(path, "mine", "*")):
+		count := int64(1)
+		if len(path) == 2 {
+			var err error
+			count, err = strconv.ParseInt(path[1], 10, 64)
+			if err != nil || count < 1 {
+				return http.StatusBadRequest, "Invalid number of blocks."
+			}
+		}
+		for i := int64(0); i < count; i++ {
+			n.mineBlock()
+		}
+		return http.StatusOK, n.info()
+	case method == "GET" && match(path, "reset"):
+		n.reset()
+		return http.StatusOK, "OK"
+	}
+	return http.StatusNotFound, "Request type not found."
+}
+
+// match checks the segments of a path, "*" matching any segment
+func match(path []string, segments ...string) bool {
+	if len(path) != len(segments) {
+		return false
+	}
+	for i, s := range segments {
+		if s != "*" && s != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *Node) info() *api.NetworkInfo {
+	return &api.NetworkInfo{
+		Network:     "arweave.localtest",
+		Version:     5,
+		Release:     1,
+		Height:      int(n.height()),
+		Current:     n.current().IndepHash,
+		Blocks:      len(n.blocks),
+		QueueLength: len(n.pending),
+	}
+}
+
+func (n *Node) handlePrice(size string, target string) (int, interface{}) {
+	s, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || s < 0 {
+		return http.StatusBadRequest, "Invalid size."
+	}
+	return http.StatusOK, n.price(s, target).String()
+}
+
+func (n *Node) postTransaction(body []byte) (int, interface{}) {
+	t := &tx.Transaction{}
+	err := json.Unmarshal(body, t)
+	if err != nil {
+		return http.StatusBadRequest, "Invalid JSON."
+	}
+	// dropped transactions can be sent again
+	known, ok := n.txs[t.Hash()]
+	if ok && known.dropped == "" {
+		return http.StatusAlreadyReported, "Transaction already processed."
+	}
+	if err = t.VerifySignature(); err != nil {
+		return http.StatusBadRequest, "Transaction verification failed."
+	}
+	if !n.validAnchor(t) {
+		return http.StatusBadRequest, "Invalid anchor (last_tx)."
+	}
+	if t.Reward().Cmp(n.price(t.DataSize(), t.Target())) < 0 {
+		return http.StatusBadRequest, "Transaction verification failed."
+	}
+	if t.Quantity().Sign() < 0 || (!t.Quantity().IsZero() && t.Target() == "") {
+		return http.StatusBadRequest, "Transaction verification failed."
+	}
+	data := t.RawData()
+	if t.Format() == 1 && int64(len(data)) != t.DataSize() {
+		return http.StatusBadRequest, "Transaction verification failed."
+	}
+	if t.Format() == 2 && len(data) > 0 && utils.EncodeToBase64(tx.GenerateDataRoot(data)) != t.DataRoot() {
+		return http.StatusBadRequest, "Transaction verification failed."
+	}
+	owner := t.OwnerAddress()
+	if n.balances[owner].Cmp(n.pendingSpend(owner).Add(t.Reward()).Add(t.Quantity())) < 0 {
+		return http.StatusBadRequest, "Overspend."
+	}
+	if known == nil {
+		n.order = append(n.order, t.Hash())
+	}
+	n.txs[t.Hash()] = &record{txn: t, height: -1}
+	n.pending = append(n.pending, t.Hash())
+	return http.StatusOK, "OK"
+}
+
+func (n *Node) getTransaction(id string) (int, interface{}) {
+	r, ok := n.txs[id]
+	if !ok || r.dropped != "" {
+		return http.StatusNotFound, "Not Found."
+	}
+	if r.height < 0 {
+		return http.StatusAccepted, "Pending"
+	}
+	return http.StatusOK, r.txn
+}
+
+func (n *Node) getStatus(id string) (int, interface{}) {
+	r, ok := n.txs[id]
+	if !ok {
+		return http.StatusNotFound, "Not Found."
+	}
+	if r.dropped != "" {
+		return http.StatusGone, r.dropped
+	}
+	if r.height < 0 {
+		return http.StatusAccepted, "Pending"
+	}
+	return http.StatusOK, &api.TransactionStatus{
+		BlockHeight:    r.height,
+		BlockIndepHash: r.block,
+		Confirmations:  n.height() - r.height + 1,
+	}
+}
+
+func (n *Node) getOffset(id string) (int, interface{}) {
+	r, ok := n.txs[id]
+	if !ok || r.height < 0 {
+		return http.StatusNotFound, "Not Found."
+	}
+	return http.StatusOK, map[string]string{
+		"offset": strconv.FormatInt(r.weaveEnd, 10),
+		"size":   strconv.FormatInt(r.txn.DataSize(), 10),
+	}
+}
+
+func (n *Node) getData(id string) (int, interface{}) {
+	r, ok := n.txs[id]
+	if !ok || r.dropped != "" {
+		return http.StatusNotFound, "Not Found."
+	}
+	data := r.txn.RawData()
+	if len(data) == 0 && r.txn.DataSize() > 0 {
+		data = n.assemble(r.txn)
+		if data == nil {
+			return http.StatusNotFound, "Data not available."
+		}
+	}
+	return http.StatusOK, utils.EncodeToBase64(data)
+}
+
+// assemble returns the data of a format 2 transaction from its chunks, nil if
+// some are missing
+func (n *Node) assemble(t *tx.Transaction) []byte {
+	data := make([]byte, 0, t.DataSize())
+	for int64(len(data)) < t.DataSize() {
+		c := n.findChunk(t.DataRoot(), int64(len(data)))
+		if c == nil {
+			return nil
+		}
+		data = append(data, c.data...)
+	}
+	return data
+}
+
+// findChunk returns the chunk of the data containing the offset
+func (n *Node) findChunk(dataRoot string, offset int64) *chunk {
+	for _, c := range n.chunks[dataRoot] {
+		if offset >= c.start && offset < c.start+int64(len(c.data)) {
+			return c
+		}
+	}
+	return nil
+}
+
+type chunkJSON struct {
+	DataRoot string `json:"data_root,omitempty"`
+	DataSize string `json:"data_size,omitempty"`
+	DataPath string `json:"data_path"`
+	Chunk    string `json:"chunk"`
+	Offset   string `json:"offset,omitempty"` // offset of the last byte of the chunk in the data
+	TxPath   string `json:"tx_path,omitempty"`
+}
+
+func (n *Node) postChunk(body []byte) (int, interface{}) {
+	cj := chunkJSON{}
+	err := json.Unmarshal(body, &cj)
+	if err != nil {
+		return http.StatusBadRequest, "Invalid JSON."
+	}
+	data, err := utils.DecodeString(cj.Chunk)
+	if err != nil || len(data) == 0 || len(data) > tx.MaxChunkSize {
+		return http.StatusBadRequest, "Invalid chunk."
+	}
+	end, err := strconv.ParseInt(cj.Offset, 10, 64)
+	if err != nil {
+		return http.StatusBadRequest, "Invalid offset."
+	}
+	known := false
+	for _, r := range n.txs {
+		if r.txn.Format() == 2 && r.txn.DataRoot() == cj.DataRoot && strconv.FormatInt(r.txn.DataSize(), 10) == cj.DataSize {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return http.StatusBadRequest, "Data root not found."
+	}
+	start := end - int64(len(data)) + 1
+	if start < 0 {
+		return http.StatusBadRequest, "Invalid offset."
+	}
+	if n.findChunk(cj.DataRoot, start) == nil {
+		n.chunks[cj.DataRoot] = append(n.chunks[cj.DataRoot], &chunk{start: start, data: data, dataPath: cj.DataPath})
+	}
+	return http.StatusOK, "OK"
+}
+
+func (n *Node) getChunk(offset string) (int, interface{}) {
+	o, err := strconv.ParseInt(offset, 10, 64)
+	if err != nil {
+		return http.StatusBadRequest, "Invalid offset."
+	}
+	for _, r := range n.txs {
+		if r.height < 0 || r.txn.Format() != 2 {
+			continue
+		}
+		start := r.weaveEnd - r.txn.DataSize() + 1
+		if o < start || o > r.weaveEnd {
+			continue
+		}
+		c := n.findChunk(r.txn.DataRoot(), o-start)
+		if c == nil {
+			break
+		}
+		return http.StatusOK, &chunkJSON{
+			Chunk:    utils.EncodeToBase64(c.data),
+			DataPath: c.dataPath,
+		}
+	}
+	return http.StatusNotFound, fmt.Sprintf("Chunk not found at offset %d.", o)
+}
@@ -0,0 +1,387 @@
+// Package arweavetest provides an in-memory arweave node for tests
+package arweavetest
+
+import (
+	"crypto/rand"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/tx"
+	"github.com/Dev43/arweave-go/utils"
+)
+
+// anchorDepth is the number of recent blocks whose hash can be used as an anchor
+const anchorDepth = 50
+
+// Node is an in-memory arweave node served over HTTP. Transactions sent to it stay
+// pending until blocks are mined with Mine. Signatures, anchors, rewards and
+// balances are checked the way a real node does, proofs of chunks are not
+type Node struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	blocks    []*api.Block
+	txs       map[string]*record
+	order     []string // transaction IDs in the order they were received
+	pending   []string
+	balances  map[string]arweave.Amount
+	lastTxs   map[string]string
+	chunks    map[string][]*chunk // chunks received, by data root
+	weaveSize int64
+	basePrice arweave.Amount
+	bytePrice arweave.Amount
+	newWallet arweave.Amount
+	faults    []*Fault
+	requests  []string
+}
+
+// record is a transaction known to the node
+type record struct {
+	txn      *tx.Transaction
+	height   int64 // -1 while the transaction is pending
+	block    string
+	dropped  string // why the transaction was dropped, if it was
+	weaveEnd int64  // offset of the last byte of the data in the weave, once mined
+}
+
+// chunk is a chunk of the data of a format 2 transaction
+type chunk struct {
+	start    int64 // offset of the first byte in the transaction data
+	data     []byte
+	dataPath string
+}
+
+// Fault makes the node answer requests with an error instead of handling them
+type Fault struct {
+	Method string        // Method of the requests affected, all if empty
+	Path   string        // Endpoint affected with its sub-paths, such as "tx" or "wallet", all if empty
+	Times  int           // Number of requests affected, all of them if 0
+	Status int           // Status code returned, the node answers normally after the delay if 0
+	Body   string        // Body returned with the status code
+	Delay  time.Duration // Delay before answering
+	Drop   bool          // Close the connection without answering
+}
+
+// NewNode starts a node with a genesis block. It must be closed with Close
+func NewNode() *Node {
+	n := &Node{
+		basePrice: arweave.NewWinston(100000),
+		bytePrice: arweave.NewWinston(1000),
+		newWallet: arweave.NewWinston(250000),
+	}
+	n.reset()
+	n.server = httptest.NewServer(http.HandlerFunc(n.serveHTTP))
+	return n
+}
+
+// URL returns the URL of the node, to give to api.Dial or transactor.NewTransactor
+func (n *Node) URL() string {
+	return n.server.URL
+}
+
+// Close stops the node
+func (n *Node) Close() {
+	n.server.Close()
+}
+
+// SetBalance sets the balance of a wallet
+func (n *Node) SetBalance(address string, amount arweave.Amount) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.balances[address] = amount
+}
+
+// Balance returns the balance of a wallet
+func (n *Node) Balance(address string) arweave.Amount {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.balances[address]
+}
+
+// SetPrice sets the price of transactions, base plus perByte for each byte of data
+func (n *Node) SetPrice(base arweave.Amount, perByte arweave.Amount) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.basePrice = base
+	n.bytePrice = perByte
+}
+
+// SetNewWalletFee sets the fee added to the price of transfers to unknown wallets
+func (n *Node) SetNewWalletFee(fee arweave.Amount) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.newWallet = fee
+}
+
+// Height returns the height of the current block
+func (n *Node) Height() int64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.height()
+}
+
+// Pending returns the IDs of the transactions waiting to be mined
+func (n *Node) Pending() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string{}, n.pending...)
+}
+
+// Transaction returns a transaction received by the node, nil if it is unknown
+func (n *Node) Transaction(id string) *tx.Transaction {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	r, ok := n.txs[id]
+	if !ok {
+		return nil
+	}
+	return r.txn
+}
+
+// Mine mines count blocks, the first one including the pending transactions.
+// Transactions whose anchor expired or whose owner cannot pay anymore are dropped
+func (n *Node) Mine(count int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for i := 0; i < count; i++ {
+		n.mineBlock()
+	}
+}
+
+// Fork replaces the last depth blocks by depth+1 new ones, as when the network
+// switches to a longer fork. The transactions of the orphaned blocks go back to
+// the mempool, and are mined again in the first new block if still valid
+func (n *Node) Fork(depth int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if depth > len(n.blocks)-1 {
+		depth = len(n.blocks) - 1
+	}
+	orphaned := []string{}
+	for i := 0; i < depth; i++ {
+		block := n.current()
+		for j := len(block.Txs) - 1; j >= 0; j-- {
+			r := n.txs[block.Txs[j]]
+			owner := r.txn.OwnerAddress()
+			n.balances[owner] = n.balances[owner].Add(r.txn.Reward()).Add(r.txn.Quantity())
+			if r.txn.Target() != "" {
+				n.balances[r.txn.Target()] = n.balances[r.txn.Target()].Sub(r.txn.Quantity())
+			}
+			n.weaveSize -= r.txn.DataSize()
+			r.height = -1
+			r.block = ""
+			orphaned = append([]string{block.Txs[j]}, orphaned...)
+		}
+		n.blocks = n.blocks[:len(n.blocks)-1]
+	}
+	n.pending = append(orphaned, n.pending...)
+	// the last transaction of the wallets may have been orphaned
+	n.lastTxs = map[string]string{}
+	for _, b := range n.blocks {
+		for _, id := range b.Txs {
+			n.lastTxs[n.txs[id].txn.OwnerAddress()] = id
+		}
+	}
+	for i := 0; i <= depth; i++ {
+		n.mineBlock()
+	}
+}
+
+// Reset wipes the blocks, transactions and wallets, starting over from a new
+// genesis block. Prices and faults are kept
+func (n *Node) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.reset()
+}
+
+// Drop removes a pending transaction from the mempool, its status then being
+// reported as dropped for the reason given
+func (n *Node) Drop(id string, reason string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	r, ok := n.txs[id]
+	if !ok || r.height >= 0 {
+		return
+	}
+	r.dropped = reason
+	n.removePending(id)
+}
+
+// AddFault makes the node fail the requests matching the fault, see Fault
+func (n *Node) AddFault(f Fault) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.faults = append(n.faults, &f)
+}
+
+// ClearFaults removes all the faults
+func (n *Node) ClearFaults() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.faults = nil
+}
+
+// Requests returns the requests received by the node, such as "GET /info"
+func (n *Node) Requests() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string{}, n.requests...)
+}
+
+func (n *Node) reset() {
+	n.blocks = nil
+	n.txs = map[string]*record{}
+	n.order = nil
+	n.pending = nil
+	n.balances = map[string]arweave.Amount{}
+	n.lastTxs = map[string]string{}
+	n.chunks = map[string][]*chunk{}
+	n.weaveSize = 0
+	n.mineBlock()
+}
+
+func (n *Node) height() int64 {
+	return int64(len(n.blocks)) - 1
+}
+
+func (n *Node) current() *api.Block {
+	return n.blocks[len(n.blocks)-1]
+}
+
+// mineBlock adds a block with the pending transactions which are still valid
+func (n *Node) mineBlock() {
+	block := &api.Block{
+		Version:        api.BlockVersion20,
+		IndepHash:      randomHash(48),
+		Hash:           randomHash(48),
+		Nonce:          randomHash(32),
+		Timestamp:      time.Now().Unix(),
+		Height:         int64(len(n.blocks)),
+		RewardAddr:     "unclaimed",
+		Txs:            []string{},
+		Diff:           big.NewInt(1),
+		CumulativeDiff: big.NewInt(int64(len(n.blocks) + 1)),
+		RewardPool:     big.NewInt(0),
+	}
+	if len(n.blocks) > 0 {
+		block.PreviousBlock = n.current().IndepHash
+		block.LastRetarget = n.current().LastRetarget
+	} else {
+		block.LastRetarget = block.Timestamp
+	}
+
+	blockSize := int64(0)
+	for _, id := range n.pending {
+		r := n.txs[id]
+		if !n.validAnchor(r.txn) {
+			r.dropped = "Invalid anchor (last_tx)."
+			continue
+		}
+		owner := r.txn.OwnerAddress()
+		cost := r.txn.Reward().Add(r.txn.Quantity())
+		if n.balances[owner].Cmp(cost) < 0 {
+			r.dropped = "Overspend."
+			continue
+		}
+		n.balances[owner] = n.balances[owner].Sub(cost)
+		if r.txn.Target() != "" {
+			n.balances[r.txn.Target()] = n.balances[r.txn.Target()].Add(r.txn.Quantity())
+		}
+		n.lastTxs[owner] = id
+		r.height = block.Height
+		r.block = block.IndepHash
+		n.weaveSize += r.txn.DataSize()
+		r.weaveEnd = n.weaveSize - 1
+		blockSize += r.txn.DataSize()
+		block.Txs = append(block.Txs, id)
+	}
+	n.pending = nil
+	block.BlockSize = big.NewInt(blockSize)
+	block.WeaveSize = big.NewInt(n.weaveSize)
+	n.blocks = append(n.blocks, block)
+}
+
+// validAnchor checks that the anchor of a transaction is a recent block or the
+// last transaction of its owner
+func (n *Node) validAnchor(t *tx.Transaction) bool {
+	if t.LastTx() == n.lastTxs[t.OwnerAddress()] {
+		return true
+	}
+	for i := len(n.blocks) - 1; i >= 0 && i >= len(n.blocks)-anchorDepth; i-- {
+		if n.blocks[i].IndepHash == t.LastTx() {
+			return true
+		}
+	}
+	return false
+}
+
+// price returns the reward needed to store size bytes, and to transfer to target
+func (n *Node) price(size int64, target string) arweave.Amount {
+	price := n.basePrice.Add(n.bytePrice.Mul(size))
+	if target != "" && !n.knownWallet(target) {
+		price = price.Add(n.newWallet)
+	}
+	return price
+}
+
+func (n *Node) knownWallet(address string) bool {
+	_, ok := n.balances[address]
+	return ok
+}
+
+// pendingSpend returns what the pending transactions of an owner will cost
+func (n *Node) pendingSpend(owner string) arweave.Amount {
+	total := arweave.Amount{}
+	for _, id := range n.pending {
+		t := n.txs[id].txn
+		if t.OwnerAddress() == owner {
+			total = total.Add(t.Reward()).Add(t.Quantity())
+		}
+	}
+	return total
+}
+
+func (n *Node) removePending(id string) {
+	for i, p := range n.pending {
+		if p == id {
+			n.pending = append(n.pending[:i], n.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// takeFault returns the fault matching the request, if any
+func (n *Node) takeFault(r *http.Request) *Fault {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	path := strings.Trim(r.URL.Path, "/")
+	for i, f := range n.faults {
+		if f.Method != "" && f.Method != r.Method {
+			continue
+		}
+		if f.Path != "" && path != f.Path && !strings.HasPrefix(path, f.Path+"/") {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				n.faults = append(n.faults[:i], n.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func randomHash(size int) string {
+	b := make([]byte, size)
+	rand.Read(b)
+	return utils.EncodeToBase64(b)
+}
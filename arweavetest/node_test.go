@@ -0,0 +1,286 @@
+package arweavetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/transactor"
+	"github.com/Dev43/arweave-go/tx"
+	"github.com/Dev43/arweave-go/utils"
+	"github.com/Dev43/arweave-go/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+const target = "1seRanklLU_1VTGkEk7P0xAwMJfA7owA1JHW5KyZKlY"
+
+func helperSetup(t *testing.T) (*Node, *transactor.Transactor, *wallet.Wallet) {
+	w := wallet.NewWallet()
+	err := w.LoadKeyFromFile(filepath.Join("..", "wallet", "testdata", "arweave-test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := NewNode()
+	n.SetBalance(w.Address(), arweave.NewWinston(1000000000))
+	tr, err := transactor.NewTransactor(n.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n, tr, w
+}
+
+func helperSend(t *testing.T, tr *transactor.Transactor, w *wallet.Wallet, b *transactor.TransactionBuilder) *tx.Transaction {
+	txn, err := b.Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := tr.SignTransaction(ctx, w, txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tr.SendTransaction(ctx, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestTransactionLifecycle(t *testing.T) {
+	n, tr, w := helperSetup(t)
+	defer n.Close()
+	c := tr.Client.(*api.Client)
+
+	txn := helperSend(t, tr, w, tr.NewTransactionBuilder(w).Target(target).Quantity(arweave.NewWinston(5000)).Data([]byte("hello")))
+	status, err := c.GetTransactionStatus(ctx, txn.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, api.StatusPending, status.State)
+	assert.Equal(t, []string{txn.Hash()}, n.Pending())
+
+	n.Mine(3)
+	mined, err := tr.WaitMined(ctx, txn, transactor.WithConfirmations(3), transactor.WithPollInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, txn.Hash(), mined.Hash())
+	data, err := c.GetData(ctx, txn.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, utils.EncodeToBase64([]byte("hello")), data)
+
+	assert.Equal(t, "5000", n.Balance(target).String())
+	spent := txn.Reward().Add(txn.Quantity())
+	assert.Equal(t, arweave.NewWinston(1000000000).Sub(spent), n.Balance(w.Address()))
+	last, err := c.LastTransaction(ctx, w.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, txn.Hash(), last)
+
+	block, err := c.GetBlockByHeight(ctx, status.BlockHeight+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{txn.Hash()}, block.Txs)
+	current, err := c.GetCurrentBlock(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(3), current.Height)
+}
+
+func TestRejectedTransactions(t *testing.T) {
+	n, tr, w := helperSetup(t)
+	defer n.Close()
+
+	cases := []struct {
+		name    string
+		builder *transactor.TransactionBuilder
+		body    string
+	}{
+		{"reward too low", tr.NewTransactionBuilder(w).Data([]byte("a")).Reward(arweave.NewWinston(1)), "Transaction verification failed."},
+		{"unknown anchor", tr.NewTransactionBuilder(w).Data([]byte("a")).Anchor(utils.EncodeToBase64(make([]byte, 48))), "Invalid anchor (last_tx)."},
+		{"overspend", tr.NewTransactionBuilder(w).Target(target).Quantity(arweave.NewWinston(1000000000)), "Overspend."},
+	}
+	for _, c := range cases {
+		txn, err := c.builder.Build(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, err := txn.Sign(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tr.SendTransaction(ctx, signed)
+		httpErr, ok := err.(*api.HTTPError)
+		if assert.True(t, ok, c.name) {
+			assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode, c.name)
+			assert.Equal(t, c.body, httpErr.Body, c.name)
+		}
+	}
+	assert.Empty(t, n.Pending())
+}
+
+func TestDroppedTransaction(t *testing.T) {
+	n, tr, w := helperSetup(t)
+	defer n.Close()
+
+	txn := helperSend(t, tr, w, tr.NewTransactionBuilder(w).Data([]byte("hello")))
+	n.Drop(txn.Hash(), "Evicted.")
+	_, err := tr.WaitMined(ctx, txn, transactor.WithPollInterval(time.Millisecond))
+	assert.Equal(t, transactor.ErrTransactionDropped, err)
+}
+
+func TestChunks(t *testing.T) {
+	n, tr, w := helperSetup(t)
+	defer n.Close()
+	c := tr.Client.(*api.Client)
+
+	data := make([]byte, tx.MaxChunkSize+1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	txn, err := tr.NewTransactionBuilder(w).Data(data).Format(2).Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := txn.Sign(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// send the transaction without its data, which is uploaded in chunks
+	header, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]interface{}{}
+	json.Unmarshal(header, &fields)
+	fields["data"] = ""
+	header, _ = json.Marshal(fields)
+	_, err = c.Commit(ctx, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offset := 0
+	for _, chunk := range tx.ChunkData(data) {
+		offset += len(chunk)
+		body, _ := json.Marshal(map[string]string{
+			"data_root": signed.DataRoot(),
+			"data_size": strconv.Itoa(len(data)),
+			"data_path": "",
+			"chunk":     utils.EncodeToBase64(chunk),
+			"offset":    strconv.Itoa(offset - 1),
+		})
+		resp, err := http.Post(n.URL()+"/chunk", "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	n.Mine(1)
+
+	got, err := c.GetData(ctx, signed.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, utils.EncodeToBase64(data), got)
+}
+
+func TestGraphQL(t *testing.T) {
+	n, tr, w := helperSetup(t)
+	defer n.Close()
+	c := tr.Client.(*api.Client)
+
+	ids := []string{}
+	for i := 0; i < 3; i++ {
+		txn := helperSend(t, tr, w, tr.NewTransactionBuilder(w).Data([]byte("hello")).Tag("Index", strconv.Itoa(i)))
+		ids = append(ids, txn.Hash())
+		n.Mine(1)
+	}
+
+	it := c.QueryTransactions(ctx, api.NewTransactionQuery().Owners(w.Address()).PageSize(2).Sort(api.HeightAsc))
+	found := []string{}
+	for it.Next() {
+		found = append(found, it.Transaction().ID)
+		assert.NotNil(t, it.Transaction().Block)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, ids, found)
+
+	it = c.QueryTransactions(ctx, api.NewTransactionQuery().Tag("Index", "1"))
+	assert.True(t, it.Next())
+	assert.Equal(t, ids[1], it.Transaction().ID)
+	assert.Equal(t, []tx.Tag{{Name: "Index", Value: "1"}}, it.Transaction().Tags)
+	assert.False(t, it.Next())
+}
+
+func TestFaults(t *testing.T) {
+	n, tr, _ := helperSetup(t)
+	defer n.Close()
+	c := tr.Client.(*api.Client)
+
+	n.AddFault(Fault{Path: "info", Times: 1, Status: http.StatusServiceUnavailable, Body: "busy"})
+	_, err := c.GetInfo(ctx)
+	assert.Equal(t, &api.HTTPError{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable", Body: "busy"}, err)
+	info, err := c.GetInfo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, info.Height)
+
+	n.AddFault(Fault{Path: "tx_anchor", Drop: true})
+	_, err = c.TxAnchor(ctx)
+	assert.Error(t, err)
+	n.ClearFaults()
+	_, err = c.TxAnchor(ctx)
+	assert.NoError(t, err)
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	n.AddFault(Fault{Path: "info", Delay: time.Second})
+	_, err = c.GetInfo(timeout)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"GET /info", "GET /info", "GET /tx_anchor", "GET /tx_anchor", "GET /info"}, n.Requests())
+}
+
+func TestDevnet(t *testing.T) {
+	n, tr, w := helperSetup(t)
+	defer n.Close()
+	c := tr.Client.(*api.Client)
+	n.SetBalance(w.Address(), arweave.Amount{})
+	tr.AutoMint = true
+	tr.AutoMine = true
+
+	txn := helperSend(t, tr, w, tr.NewTransactionBuilder(w).Target(target).Quantity(arweave.NewWinston(5000)))
+	status, err := c.GetTransactionStatus(ctx, txn.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, status.Confirmed(1))
+	assert.True(t, n.Balance(w.Address()).IsZero(), "only the missing funds are minted")
+
+	balance, err := c.Mint(ctx, target, arweave.NewWinston(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "6000", balance.String())
+	assert.NoError(t, c.Mine(ctx, 2))
+	assert.Equal(t, int64(3), n.Height())
+
+	assert.NoError(t, c.Reset(ctx))
+	assert.Equal(t, int64(0), n.Height())
+	assert.True(t, n.Balance(target).IsZero())
+}
@@ -1,8 +1,13 @@
 module github.com/Dev43/arweave-go
 
-go 1.12
+go 1.15
 
 require (
 	github.com/mendsley/gojwk v0.0.0-20141217222730-4d5ec6e58103
-	github.com/stretchr/testify v1.6.1
+	github.com/prometheus/client_golang v1.11.1
+	github.com/stretchr/testify v1.7.0
+	go.etcd.io/bbolt v1.3.6
+	go.opentelemetry.io/otel v1.0.0
+	go.opentelemetry.io/otel/sdk v1.0.0
+	go.opentelemetry.io/otel/trace v1.0.0
 )
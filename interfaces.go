@@ -1,7 +1,10 @@
 // Package arweave defines interfaces for interacting with the Arweave Blockchain.
 package arweave
 
-import "math/big"
+import (
+	"math/big"
+	"time"
+)
 
 // WalletSigner is the interface needed to be able to sign an arweave
 type WalletSigner interface {
@@ -10,3 +13,41 @@ type WalletSigner interface {
 	Address() string
 	PubKeyModulus() *big.Int
 }
+
+// Logger is the interface used to log the events of the SDK. Fields are given
+// as alternating keys and values after the message, the way *slog.Logger does,
+// which satisfies this interface
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// MetricsCollector is the interface receiving the measurements of the SDK
+type MetricsCollector interface {
+	// ObserveRequest records a request made to an endpoint of a node. The status
+	// is 0 when no response was received
+	ObserveRequest(node string, endpoint string, status int, duration time.Duration)
+	// AddBytesUploaded records the size of a request body sent to a node
+	AddBytesUploaded(node string, endpoint string, bytes int)
+	// AddPendingConfirmations changes the number of transactions waiting to be confirmed
+	AddPendingConfirmations(delta int)
+	// AddChunkRetries records chunk uploads retried after a failure
+	AddChunkRetries(count int)
+}
+
+// NopLogger is a Logger discarding everything, used when none is set
+type NopLogger struct{}
+
+// Debug does nothing
+func (NopLogger) Debug(msg string, args ...interface{}) {}
+
+// Info does nothing
+func (NopLogger) Info(msg string, args ...interface{}) {}
+
+// Warn does nothing
+func (NopLogger) Warn(msg string, args ...interface{}) {}
+
+// Error does nothing
+func (NopLogger) Error(msg string, args ...interface{}) {}
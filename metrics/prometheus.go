@@ -0,0 +1,93 @@
+// Package metrics exposes the measurements of the SDK as Prometheus metrics.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusCollector implements arweave.MetricsCollector with Prometheus metrics.
+// It can be set on an api.Client with SetMetrics and on a Transactor with its
+// Metrics field
+type PrometheusCollector struct {
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+	uploaded *prometheus.CounterVec
+	pending  prometheus.Gauge
+	retried  prometheus.Counter
+}
+
+// NewPrometheusCollector creates the metrics and registers them with reg
+func NewPrometheusCollector(reg prometheus.Registerer) (*PrometheusCollector, error) {
+	c := &PrometheusCollector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "arweave",
+			Name:      "requests_total",
+			Help:      "Number of requests sent to arweave nodes.",
+		}, []string{"node", "endpoint", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "arweave",
+			Name:      "request_duration_seconds",
+			Help:      "Time taken by arweave nodes to answer requests.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+		}, []string{"node", "endpoint"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "arweave",
+			Name:      "request_errors_total",
+			Help:      "Number of requests which failed, by status code. The status is \"none\" when no response was received.",
+		}, []string{"node", "endpoint", "status"}),
+		uploaded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "arweave",
+			Name:      "uploaded_bytes_total",
+			Help:      "Number of bytes sent to arweave nodes.",
+		}, []string{"node", "endpoint"}),
+		pending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "arweave",
+			Name:      "transactions_pending_confirmation",
+			Help:      "Number of transactions waited for until they are confirmed.",
+		}),
+		retried: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "arweave",
+			Name:      "chunk_retries_total",
+			Help:      "Number of chunk uploads retried after a failure.",
+		}),
+	}
+	for _, collector := range []prometheus.Collector{c.requests, c.latency, c.errors, c.uploaded, c.pending, c.retried} {
+		err := reg.Register(collector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// ObserveRequest records a request made to an endpoint of a node
+func (c *PrometheusCollector) ObserveRequest(node string, endpoint string, status int, duration time.Duration) {
+	statusLabel := "none"
+	if status != 0 {
+		statusLabel = strconv.Itoa(status)
+	}
+	c.requests.WithLabelValues(node, endpoint, statusLabel).Inc()
+	c.latency.WithLabelValues(node, endpoint).Observe(duration.Seconds())
+	if status < 200 || status >= 300 {
+		c.errors.WithLabelValues(node, endpoint, statusLabel).Inc()
+	}
+}
+
+// AddBytesUploaded records the size of a request body sent to a node
+func (c *PrometheusCollector) AddBytesUploaded(node string, endpoint string, bytes int) {
+	c.uploaded.WithLabelValues(node, endpoint).Add(float64(bytes))
+}
+
+// AddPendingConfirmations changes the number of transactions waiting to be confirmed
+func (c *PrometheusCollector) AddPendingConfirmations(delta int) {
+	c.pending.Add(float64(delta))
+}
+
+// AddChunkRetries records chunk uploads retried after a failure
+func (c *PrometheusCollector) AddChunkRetries(count int) {
+	c.retried.Add(float64(count))
+}
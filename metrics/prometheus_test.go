@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Dev43/arweave-go/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusCollector(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tx" {
+			w.Write([]byte("OK"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	collector, err := NewPrometheusCollector(prometheus.NewRegistry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := api.Dial(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetMetrics(collector)
+
+	ctx := context.TODO()
+	c.Commit(ctx, []byte("{}"))
+	c.GetBalance(ctx, "WWMgP35v3BRciaex-sdy-VRfd254M8bqK52v0zRH0Lc")
+	c.GetBalance(ctx, "pfJXiTwwjQwSJF9VT1ZK6kauvobWuKKLUzjz29R1gbQ")
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(collector.requests.WithLabelValues(srv.URL, "tx", "200")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(collector.requests.WithLabelValues(srv.URL, "wallet/{id}/balance", "404")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(collector.errors.WithLabelValues(srv.URL, "wallet/{id}/balance", "404")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(collector.errors.WithLabelValues(srv.URL, "tx", "200")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(collector.uploaded.WithLabelValues(srv.URL, "tx")))
+
+	collector.AddPendingConfirmations(2)
+	collector.AddPendingConfirmations(-1)
+	assert.Equal(t, 1.0, testutil.ToFloat64(collector.pending))
+
+	collector.AddChunkRetries(1)
+	collector.AddChunkRetries(2)
+	assert.Equal(t, 3.0, testutil.ToFloat64(collector.retried))
+}
@@ -0,0 +1,535 @@
+// Package outbox records the transactions of a process in a local BoltDB file,
+// so that sending and waiting for them can resume after a restart: Resume sends
+// the signed transactions again and tracks the sent ones with a rebroadcaster,
+// WaitSent waits for the sent ones to be mined
+package outbox
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/transactor"
+	"github.com/Dev43/arweave-go/tx"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	entriesBucket = []byte("entries")
+	idsBucket     = []byte("ids") // transaction ID to entry key
+)
+
+// ErrNotFound is returned for unknown entries
+var ErrNotFound = errors.New("outbox entry not found")
+
+// State is the progress of a transaction of the outbox
+type State string
+
+const (
+	// StateCreated transactions are not signed yet
+	StateCreated State = "created"
+	// StateSigned transactions are signed but were not sent
+	StateSigned State = "signed"
+	// StateSent transactions were accepted by the node and are waiting to be mined
+	StateSent State = "sent"
+	// StateMined transactions are included in a block
+	StateMined State = "mined"
+	// StateExpired transactions can no longer be mined since their anchor expired
+	StateExpired State = "expired"
+	// StateFailed transactions were rejected by the node when sent
+	StateFailed State = "failed"
+)
+
+// Final returns true if the transaction will not make progress anymore
+func (s State) Final() bool {
+	return s == StateMined || s == StateExpired || s == StateFailed
+}
+
+// Entry is a transaction recorded in the outbox. If the transaction is re-signed
+// by a rebroadcaster, the entry follows the replacement
+type Entry struct {
+	Key       uint64
+	State     State
+	Tx        *tx.Transaction
+	Status    *api.TransactionStatus // Last status received from the node, if any
+	Error     string                 // Why the transaction failed or expired
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type entryJSON struct {
+	State     State           `json:"state"`
+	Tx        *tx.Transaction `json:"tx"`
+	Status    *statusJSON     `json:"status,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// statusJSON stores a transaction status with its state and reason, which the
+// JSON of api.TransactionStatus leaves out as nodes give them apart
+type statusJSON struct {
+	State          api.TransactionState `json:"state"`
+	BlockHeight    int64                `json:"block_height,omitempty"`
+	BlockIndepHash string               `json:"block_indep_hash,omitempty"`
+	Confirmations  int64                `json:"confirmations,omitempty"`
+	Reason         string               `json:"reason,omitempty"`
+}
+
+// Outbox is a store of transactions, safe for concurrent use
+type Outbox struct {
+	db *bolt.DB
+}
+
+// Open opens the outbox stored in the file at path, creating it if needed. Only
+// one process can open it at a time
+func Open(path string) (*Outbox, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(btx *bolt.Tx) error {
+		if _, err := btx.CreateBucketIfNotExists(entriesBucket); err != nil {
+			return err
+		}
+		_, err := btx.CreateBucketIfNotExists(idsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Outbox{db: db}, nil
+}
+
+// Close closes the file of the outbox
+func (o *Outbox) Close() error {
+	return o.db.Close()
+}
+
+// Add records a transaction, as created or signed depending on whether it has a
+// signature, and returns the key of its entry
+func (o *Outbox) Add(txn *tx.Transaction) (uint64, error) {
+	var key uint64
+	err := o.db.Update(func(btx *bolt.Tx) error {
+		var err error
+		key, err = btx.Bucket(entriesBucket).NextSequence()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		e := &Entry{Key: key, State: StateCreated, Tx: txn, CreatedAt: now, UpdatedAt: now}
+		if len(txn.Signature()) > 0 {
+			e.State = StateSigned
+		}
+		return put(btx, e, "")
+	})
+	return key, err
+}
+
+// Get returns the entry with the key
+func (o *Outbox) Get(key uint64) (*Entry, error) {
+	var e *Entry
+	err := o.db.View(func(btx *bolt.Tx) error {
+		var err error
+		e, err = get(btx, key)
+		return err
+	})
+	return e, err
+}
+
+// GetByID returns the entry of the transaction with the ID
+func (o *Outbox) GetByID(txID string) (*Entry, error) {
+	var e *Entry
+	err := o.db.View(func(btx *bolt.Tx) error {
+		k := btx.Bucket(idsBucket).Get([]byte(txID))
+		if k == nil {
+			return ErrNotFound
+		}
+		var err error
+		e, err = get(btx, binary.BigEndian.Uint64(k))
+		return err
+	})
+	return e, err
+}
+
+// Update changes an entry with fn within a transaction of the store
+func (o *Outbox) Update(key uint64, fn func(*Entry) error) error {
+	return o.db.Update(func(btx *bolt.Tx) error {
+		e, err := get(btx, key)
+		if err != nil {
+			return err
+		}
+		oldID := e.Tx.Hash()
+		err = fn(e)
+		if err != nil {
+			return err
+		}
+		e.UpdatedAt = time.Now()
+		return put(btx, e, oldID)
+	})
+}
+
+// Delete removes an entry
+func (o *Outbox) Delete(key uint64) error {
+	return o.db.Update(func(btx *bolt.Tx) error {
+		e, err := get(btx, key)
+		if err != nil {
+			return err
+		}
+		if e.Tx.Hash() != "" {
+			if err := btx.Bucket(idsBucket).Delete([]byte(e.Tx.Hash())); err != nil {
+				return err
+			}
+		}
+		return btx.Bucket(entriesBucket).Delete(encodeKey(key))
+	})
+}
+
+// Entries returns the entries, in the order they were added. Entries in a final
+// state are skipped unless all is true
+func (o *Outbox) Entries(all bool) ([]*Entry, error) {
+	entries := []*Entry{}
+	err := o.db.View(func(btx *bolt.Tx) error {
+		return btx.Bucket(entriesBucket).ForEach(func(k []byte, v []byte) error {
+			e, err := decode(k, v)
+			if err != nil {
+				return err
+			}
+			if all || !e.State.Final() {
+				entries = append(entries, e)
+			}
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// Sign signs the transaction of a created entry with the wallet
+func (o *Outbox) Sign(ctx context.Context, tr *transactor.Transactor, key uint64, w arweave.WalletSigner) (*tx.Transaction, error) {
+	var signed *tx.Transaction
+	err := o.Update(key, func(e *Entry) error {
+		if e.State != StateCreated {
+			return errors.New("outbox entry is already signed")
+		}
+		var err error
+		signed, err = tr.SignTransaction(ctx, w, e.Tx)
+		if err != nil {
+			return err
+		}
+		e.Tx = signed
+		e.State = StateSigned
+		return nil
+	})
+	return signed, err
+}
+
+// Send sends the transaction of a signed entry. The entry is marked as sent, or
+// as failed if the node rejected it. Any other error, such as ctx being done or
+// the node being unreachable, leaves it signed so that it can be sent again
+func (o *Outbox) Send(ctx context.Context, tr *transactor.Transactor, key uint64) error {
+	e, err := o.Get(key)
+	if err != nil {
+		return err
+	}
+	if e.State != StateSigned {
+		return errors.New("outbox entry is not signed or already sent")
+	}
+	_, sendErr := tr.SendTransaction(ctx, e.Tx)
+	if sendErr != nil && !isRejection(sendErr) {
+		return sendErr
+	}
+	err = o.Update(key, func(e *Entry) error {
+		if sendErr != nil {
+			e.State = StateFailed
+			e.Error = sendErr.Error()
+			return nil
+		}
+		e.State = StateSent
+		return nil
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	return err
+}
+
+// isRejection returns true if the node answered that the transaction is invalid,
+// a 4xx status code other than 429 which only asks to slow down
+func isRejection(err error) bool {
+	httpErr, ok := err.(*api.HTTPError)
+	return ok && httpErr.StatusCode >= 400 && httpErr.StatusCode < 500 && httpErr.StatusCode != http.StatusTooManyRequests
+}
+
+// WaitMined waits for the transaction of a sent entry with tr.WaitMined and
+// records the outcome
+func (o *Outbox) WaitMined(ctx context.Context, tr *transactor.Transactor, key uint64, opts ...transactor.WaitOption) (*tx.Transaction, error) {
+	e, err := o.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	mined, waitErr := tr.WaitMined(ctx, e.Tx, opts...)
+	if waitErr != nil && waitErr != transactor.ErrTransactionDropped {
+		return nil, waitErr
+	}
+	var status *api.TransactionStatus
+	if waitErr == nil {
+		status, err = tr.Client.GetTransactionStatus(ctx, e.Tx.Hash())
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = o.Update(key, func(e *Entry) error {
+		if waitErr != nil {
+			// a dropped transaction can still be sent again by a rebroadcaster
+			e.Error = waitErr.Error()
+			return nil
+		}
+		e.State = StateMined
+		e.Status = status
+		return nil
+	})
+	if waitErr != nil {
+		return nil, waitErr
+	}
+	return mined, err
+}
+
+// RecordEvent keeps the entries up to date with an event of a rebroadcaster,
+// following the replacement of re-signed transactions. Events of transactions
+// which are not in the outbox are ignored
+func (o *Outbox) RecordEvent(event transactor.RebroadcastEvent) error {
+	switch event.Type {
+	case transactor.RebroadcastResigned, transactor.RebroadcastMined, transactor.RebroadcastExpired:
+	default:
+		return nil
+	}
+	e, err := o.GetByID(event.TxID)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return o.Update(e.Key, func(e *Entry) error {
+		switch event.Type {
+		case transactor.RebroadcastResigned:
+			e.Tx = event.Replacement
+			e.State = StateSent
+		case transactor.RebroadcastMined:
+			e.State = StateMined
+		case transactor.RebroadcastExpired:
+			e.State = StateExpired
+			if event.Err != nil {
+				e.Error = event.Err.Error()
+			}
+		}
+		return nil
+	})
+}
+
+// EventRecorder returns a function recording the events of a rebroadcaster with
+// RecordEvent, meant to be given to transactor.WithRebroadcastEvents. The events
+// which could not be recorded are passed to onError with the error, if it is not nil
+func (o *Outbox) EventRecorder(onError func(transactor.RebroadcastEvent, error)) func(transactor.RebroadcastEvent) {
+	return func(event transactor.RebroadcastEvent) {
+		err := o.RecordEvent(event)
+		if err != nil && onError != nil {
+			onError(event, err)
+		}
+	}
+}
+
+// ResumeError reports the entries Resume could not send because the node
+// rejected their transaction. They are marked as failed
+type ResumeError struct {
+	Errors map[uint64]error // By entry key
+}
+
+func (e *ResumeError) Error() string {
+	return "outbox entries rejected: " + formatErrors(e.Errors)
+}
+
+// WaitError reports the entries WaitSent could not see mined
+type WaitError struct {
+	Errors map[uint64]error // By entry key
+}
+
+func (e *WaitError) Error() string {
+	return "waiting for outbox entries failed: " + formatErrors(e.Errors)
+}
+
+// formatErrors lists the errors of entries in the order of their keys
+func formatErrors(errs map[uint64]error) string {
+	keys := make([]uint64, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	msgs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		msgs = append(msgs, fmt.Sprintf("entry %d: %v", key, errs[key]))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Resume carries on with the entries in flight after a restart: signed
+// transactions are sent, and sent ones are tracked by the rebroadcaster, with the
+// wallet to re-sign them if it is not nil. Created transactions are left to sign.
+// Resume stops at the first error leaving an entry signed, such as the node being
+// unreachable. Entries rejected by the node are reported in a *ResumeError once
+// the others are resumed.
+//
+// Resume does not wait for the transactions: the sent entries are only marked as
+// mined by the events of the rebroadcaster, if they are recorded with
+// EventRecorder, or by waiting for them with WaitSent or WaitMined
+func (o *Outbox) Resume(ctx context.Context, tr *transactor.Transactor, r *transactor.Rebroadcaster, w arweave.WalletSigner) error {
+	entries, err := o.Entries(false)
+	if err != nil {
+		return err
+	}
+	rejected := &ResumeError{Errors: map[uint64]error{}}
+	for _, e := range entries {
+		switch e.State {
+		case StateSigned:
+			err = o.Send(ctx, tr, e.Key)
+			if err != nil && !isRejection(err) {
+				return err
+			}
+			if err != nil {
+				rejected.Errors[e.Key] = err
+				continue
+			}
+			r.Track(e.Tx, w)
+		case StateSent:
+			r.Track(e.Tx, w)
+		}
+	}
+	if len(rejected.Errors) > 0 {
+		return rejected
+	}
+	return nil
+}
+
+// WaitSent waits for the transactions of all the sent entries with WaitMined,
+// concurrently, and records their outcome. It is meant to be called after
+// Resume, to wait again for the transactions sent before a restart. The entries
+// which could not be waited for, such as the ones whose transaction was dropped,
+// are reported in a *WaitError once the others are mined
+func (o *Outbox) WaitSent(ctx context.Context, tr *transactor.Transactor, opts ...transactor.WaitOption) error {
+	entries, err := o.Entries(false)
+	if err != nil {
+		return err
+	}
+	failed := &WaitError{Errors: map[uint64]error{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		if e.State != StateSent {
+			continue
+		}
+		wg.Add(1)
+		go func(key uint64) {
+			defer wg.Done()
+			_, err := o.WaitMined(ctx, tr, key, opts...)
+			if err != nil {
+				mu.Lock()
+				failed.Errors[key] = err
+				mu.Unlock()
+			}
+		}(e.Key)
+	}
+	wg.Wait()
+	if len(failed.Errors) > 0 {
+		return failed
+	}
+	return nil
+}
+
+func get(btx *bolt.Tx, key uint64) (*Entry, error) {
+	k := encodeKey(key)
+	v := btx.Bucket(entriesBucket).Get(k)
+	if v == nil {
+		return nil, ErrNotFound
+	}
+	return decode(k, v)
+}
+
+// put stores an entry, updating the ID index if its transaction changed
+func put(btx *bolt.Tx, e *Entry, oldID string) error {
+	ej := &entryJSON{
+		State:     e.State,
+		Tx:        e.Tx,
+		Error:     e.Error,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+	if e.Status != nil {
+		ej.Status = &statusJSON{
+			State:          e.Status.State,
+			BlockHeight:    e.Status.BlockHeight,
+			BlockIndepHash: e.Status.BlockIndepHash,
+			Confirmations:  e.Status.Confirmations,
+			Reason:         e.Status.Reason,
+		}
+	}
+	v, err := json.Marshal(ej)
+	if err != nil {
+		return err
+	}
+	k := encodeKey(e.Key)
+	ids := btx.Bucket(idsBucket)
+	if oldID != "" && oldID != e.Tx.Hash() {
+		if err := ids.Delete([]byte(oldID)); err != nil {
+			return err
+		}
+	}
+	if e.Tx.Hash() != "" {
+		if err := ids.Put([]byte(e.Tx.Hash()), k); err != nil {
+			return err
+		}
+	}
+	return btx.Bucket(entriesBucket).Put(k, v)
+}
+
+func decode(k []byte, v []byte) (*Entry, error) {
+	ej := entryJSON{}
+	err := json.Unmarshal(v, &ej)
+	if err != nil {
+		return nil, err
+	}
+	e := &Entry{
+		Key:       binary.BigEndian.Uint64(k),
+		State:     ej.State,
+		Tx:        ej.Tx,
+		Error:     ej.Error,
+		CreatedAt: ej.CreatedAt,
+		UpdatedAt: ej.UpdatedAt,
+	}
+	if ej.Status != nil {
+		e.Status = &api.TransactionStatus{
+			State:          ej.Status.State,
+			BlockHeight:    ej.Status.BlockHeight,
+			BlockIndepHash: ej.Status.BlockIndepHash,
+			Confirmations:  ej.Status.Confirmations,
+			Reason:         ej.Status.Reason,
+		}
+	}
+	return e, nil
+}
+
+// encodeKey encodes keys in big endian so that entries are sorted by key
+func encodeKey(key uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, key)
+	return k
+}
@@ -0,0 +1,322 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/arweavetest"
+	"github.com/Dev43/arweave-go/transactor"
+	"github.com/Dev43/arweave-go/tx"
+	"github.com/Dev43/arweave-go/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+func TestOutboxResume(t *testing.T) {
+	w := wallet.NewWallet()
+	err := w.LoadKeyFromFile(filepath.Join("..", "wallet", "testdata", "arweave-test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := arweavetest.NewNode()
+	defer node.Close()
+	node.SetBalance(w.Address(), arweave.NewWinston(1000000000))
+	tr, err := transactor.NewTransactor(node.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox.db")
+
+	o, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []uint64{}
+	for _, data := range []string{"sent", "signed", "created"} {
+		txn, err := tr.NewTransactionBuilder(w).Data([]byte(data)).Build(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := o.Add(txn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	_, err = o.Sign(ctx, tr, keys[0], w)
+	assert.NoError(t, err)
+	assert.NoError(t, o.Send(ctx, tr, keys[0]))
+	signed, err := o.Sign(ctx, tr, keys[1], w)
+	assert.NoError(t, err)
+	assert.NoError(t, o.Close())
+
+	// the process restarts
+	o, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	entries, err := o.Entries(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := []State{}
+	for _, e := range entries {
+		states = append(states, e.State)
+	}
+	assert.Equal(t, []State{StateSent, StateSigned, StateCreated}, states)
+	e, err := o.GetByID(signed.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, keys[1], e.Key)
+
+	r := tr.NewRebroadcaster(transactor.WithRebroadcastEvents(o.EventRecorder(nil)))
+	assert.NoError(t, o.Resume(ctx, tr, r, w))
+	assert.Len(t, node.Pending(), 2)
+	assert.Len(t, r.Tracked(), 2)
+
+	// the transactions sent before and after the restart are waited for
+	node.Mine(1)
+	assert.NoError(t, o.WaitSent(ctx, tr, transactor.WithPollInterval(time.Millisecond)))
+	for _, key := range keys[:2] {
+		e, err = o.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, StateMined, e.State)
+		assert.Equal(t, api.StatusMined, e.Status.State)
+		assert.True(t, e.Status.Confirmed(1))
+		assert.Equal(t, int64(1), e.Status.BlockHeight)
+	}
+	r.Check(ctx)
+	assert.Empty(t, r.Tracked())
+
+	entries, err = o.Entries(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, entries, 1, "only the created transaction is left")
+	assert.Equal(t, keys[2], entries[0].Key)
+}
+
+// helperSetup opens an outbox in a temporary directory, with a transactor of a
+// node where the test wallet is funded
+func helperSetup(t *testing.T) (*wallet.Wallet, *arweavetest.Node, *transactor.Transactor, *Outbox, func()) {
+	w := wallet.NewWallet()
+	err := w.LoadKeyFromFile(filepath.Join("..", "wallet", "testdata", "arweave-test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := arweavetest.NewNode()
+	node.SetBalance(w.Address(), arweave.NewWinston(1000000000))
+	tr, err := transactor.NewTransactor(node.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	o, err := Open(filepath.Join(dir, "outbox.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w, node, tr, o, func() {
+		o.Close()
+		node.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func helperSigned(t *testing.T, tr *transactor.Transactor, w *wallet.Wallet, data string) *tx.Transaction {
+	txn, err := tr.NewTransactionBuilder(w).Data([]byte(data)).Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn, err = tr.SignTransaction(ctx, w, txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return txn
+}
+
+func TestOutboxSendCanceled(t *testing.T) {
+	w, node, tr, o, cleanup := helperSetup(t)
+	defer cleanup()
+	key, err := o.Add(helperSigned(t, tr, w, "hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the deadline expires while the transaction is posted
+	node.AddFault(arweavetest.Fault{Method: "POST", Path: "tx", Times: 1, Delay: time.Second})
+	sendCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	assert.Error(t, o.Send(sendCtx, tr, key))
+	e, err := o.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, StateSigned, e.State)
+
+	assert.NoError(t, o.Send(ctx, tr, key))
+	e, err = o.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, StateSent, e.State)
+}
+
+func TestOutboxSendErrors(t *testing.T) {
+	w, node, tr, o, cleanup := helperSetup(t)
+	defer cleanup()
+	cases := []struct {
+		status int
+		state  State
+	}{
+		{503, StateSigned},
+		{429, StateSigned},
+		{400, StateFailed},
+	}
+	for i, c := range cases {
+		key, err := o.Add(helperSigned(t, tr, w, fmt.Sprintf("tx %d", i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		node.AddFault(arweavetest.Fault{Method: "POST", Path: "tx", Times: 1, Status: c.status, Body: "Invalid."})
+		assert.Error(t, o.Send(ctx, tr, key))
+		e, err := o.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, c.state, e.State, "wrong state after a %d", c.status)
+		if c.state == StateFailed {
+			assert.Contains(t, e.Error, "Invalid.")
+		}
+	}
+}
+
+func TestOutboxRecordEvent(t *testing.T) {
+	w, _, tr, o, cleanup := helperSetup(t)
+	defer cleanup()
+	txs := []*tx.Transaction{}
+	keys := []uint64{}
+	for _, data := range []string{"expired", "mined", "resigned"} {
+		txn := helperSigned(t, tr, w, data)
+		key, err := o.Add(txn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, txn)
+		keys = append(keys, key)
+	}
+	replacement := helperSigned(t, tr, w, "replacement")
+
+	assert.NoError(t, o.RecordEvent(transactor.RebroadcastEvent{Type: transactor.RebroadcastExpired, TxID: txs[0].Hash()}))
+	assert.NoError(t, o.RecordEvent(transactor.RebroadcastEvent{Type: transactor.RebroadcastMined, TxID: txs[1].Hash()}))
+	assert.NoError(t, o.RecordEvent(transactor.RebroadcastEvent{Type: transactor.RebroadcastResigned, TxID: txs[2].Hash(), Replacement: replacement}))
+	assert.NoError(t, o.RecordEvent(transactor.RebroadcastEvent{Type: transactor.RebroadcastMined, TxID: "unknown"}))
+	assert.NoError(t, o.RecordEvent(transactor.RebroadcastEvent{Type: transactor.RebroadcastPosted, TxID: txs[0].Hash()}))
+
+	states := []State{}
+	for _, key := range keys {
+		e, err := o.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, e.State)
+	}
+	assert.Equal(t, []State{StateExpired, StateMined, StateSent}, states)
+	e, err := o.GetByID(replacement.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, keys[2], e.Key)
+	_, err = o.GetByID(txs[2].Hash())
+	assert.Equal(t, ErrNotFound, err)
+
+	// errors of the store are reported
+	failed := []error{}
+	record := o.EventRecorder(func(e transactor.RebroadcastEvent, err error) { failed = append(failed, err) })
+	assert.NoError(t, o.Close())
+	record(transactor.RebroadcastEvent{Type: transactor.RebroadcastMined, TxID: replacement.Hash()})
+	assert.Len(t, failed, 1)
+}
+
+func TestOutboxWaitSentDropped(t *testing.T) {
+	w, node, tr, o, cleanup := helperSetup(t)
+	defer cleanup()
+	keys := []uint64{}
+	for _, data := range []string{"dropped", "mined"} {
+		key, err := o.Add(helperSigned(t, tr, w, data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, o.Send(ctx, tr, key))
+		keys = append(keys, key)
+	}
+	dropped, err := o.Get(keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.Drop(dropped.Tx.Hash(), "Evicted.")
+	node.Mine(1)
+
+	err = o.WaitSent(ctx, tr, transactor.WithPollInterval(time.Millisecond))
+	waitErr, ok := err.(*WaitError)
+	if !ok {
+		t.Fatalf("expected a *WaitError, got %v", err)
+	}
+	assert.Equal(t, map[uint64]error{keys[0]: transactor.ErrTransactionDropped}, waitErr.Errors)
+	e, err := o.Get(keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, StateSent, e.State, "a dropped transaction can be sent again")
+	e, err = o.Get(keys[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, StateMined, e.State)
+}
+
+func TestOutboxResumeRejected(t *testing.T) {
+	w, node, tr, o, cleanup := helperSetup(t)
+	defer cleanup()
+	keys := []uint64{}
+	for _, data := range []string{"rejected", "accepted"} {
+		key, err := o.Add(helperSigned(t, tr, w, data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+	node.AddFault(arweavetest.Fault{Method: "POST", Path: "tx", Times: 1, Status: 400, Body: "Invalid."})
+	r := tr.NewRebroadcaster()
+	err := o.Resume(ctx, tr, r, w)
+	resumeErr, ok := err.(*ResumeError)
+	if !ok {
+		t.Fatalf("expected a *ResumeError, got %v", err)
+	}
+	assert.Len(t, resumeErr.Errors, 1)
+	assert.Contains(t, resumeErr.Errors, keys[0])
+	assert.Len(t, r.Tracked(), 1, "the other entries are resumed")
+
+	e, err := o.Get(keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, StateFailed, e.State)
+}
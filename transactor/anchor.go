@@ -0,0 +1,150 @@
+package transactor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Dev43/arweave-go"
+)
+
+// DefaultAnchorTTL is how long CachedAnchor reuses a block anchor. Nodes accept
+// anchors from the last 50 blocks, about 100 minutes, the margin leaving time for
+// the transactions to be mined
+const DefaultAnchorTTL = 10 * time.Minute
+
+// anchorRequestTimeout bounds the anchor requests of CachedAnchor, which do not
+// depend on the context of any caller
+const anchorRequestTimeout = time.Minute
+
+// AnchorProvider gives the anchor (last_tx field) of the transactions created by
+// the transactor for a wallet
+type AnchorProvider interface {
+	Anchor(ctx context.Context, address string) (string, error)
+}
+
+// anchor returns the anchor of a new transaction of the wallet, requesting a
+// block anchor from the node if no provider is set
+func (tr *Transactor) anchor(ctx context.Context, address string) (string, error) {
+	if tr.Anchors != nil {
+		return tr.Anchors.Anchor(ctx, address)
+	}
+	return tr.Client.TxAnchor(ctx)
+}
+
+// CachedAnchor serves the same block anchor to every transaction until it is
+// older than its TTL, so that sending many transactions does not request one
+// from the node each time. It is safe for concurrent use
+type CachedAnchor struct {
+	// Logger is optional, it receives the failures of the background refreshes
+	Logger arweave.Logger
+
+	client ClientCaller
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	anchor    string
+	fetchedAt time.Time
+	fetching  *anchorFetch // request in flight, shared by the callers waiting for it
+}
+
+type anchorFetch struct {
+	done   chan struct{}
+	anchor string
+	err    error
+}
+
+// NewCachedAnchor creates a provider caching the anchors of the client for ttl,
+// DefaultAnchorTTL if 0 or negative
+func NewCachedAnchor(client ClientCaller, ttl time.Duration) *CachedAnchor {
+	if ttl <= 0 {
+		ttl = DefaultAnchorTTL
+	}
+	return &CachedAnchor{client: client, ttl: ttl}
+}
+
+// Anchor returns the cached anchor, requesting a new one if it expired. The
+// address is ignored, block anchors are valid for every wallet
+func (a *CachedAnchor) Anchor(ctx context.Context, address string) (string, error) {
+	a.mu.Lock()
+	if a.anchor != "" && time.Since(a.fetchedAt) < a.ttl {
+		defer a.mu.Unlock()
+		return a.anchor, nil
+	}
+	a.mu.Unlock()
+	return a.Refresh(ctx)
+}
+
+// Refresh requests a new anchor from the node. Concurrent calls share the same
+// request, the node is not called with the lock of the cache held so that
+// callers of Anchor are served the current anchor meanwhile. The request goes on
+// when ctx is done, only this caller stops waiting for it
+func (a *CachedAnchor) Refresh(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	f := a.fetching
+	if f == nil {
+		f = &anchorFetch{done: make(chan struct{})}
+		a.fetching = f
+		go a.fetch(f)
+	}
+	a.mu.Unlock()
+	select {
+	case <-f.done:
+		return f.anchor, f.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (a *CachedAnchor) fetch(f *anchorFetch) {
+	ctx, cancel := context.WithTimeout(context.Background(), anchorRequestTimeout)
+	defer cancel()
+	f.anchor, f.err = a.client.TxAnchor(ctx)
+	a.mu.Lock()
+	if f.err == nil {
+		a.anchor = f.anchor
+		a.fetchedAt = time.Now()
+	}
+	a.fetching = nil
+	a.mu.Unlock()
+	close(f.done)
+}
+
+// Start refreshes the anchor in the background every half TTL, so that callers
+// of Anchor never wait for the node, until ctx is done. It returns the error of
+// the first refresh, if any. Failed refreshes are reported to the Logger and
+// retried on the next tick, the current anchor being served until it expires
+func (a *CachedAnchor) Start(ctx context.Context) error {
+	_, err := a.Refresh(ctx)
+	interval := a.ttl / 2
+	if interval <= 0 {
+		interval = a.ttl
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := a.Refresh(ctx); err != nil && a.Logger != nil {
+					a.Logger.Warn("refreshing anchor failed", "error", err)
+				}
+			}
+		}
+	}()
+	return err
+}
+
+// LastTxAnchor anchors the transactions to the last transaction of their wallet.
+// A transaction anchored this way is only valid once the previous one is mined,
+// which orders the transactions of the wallet, one per block
+type LastTxAnchor struct {
+	Client ClientCaller
+}
+
+// Anchor returns the last transaction of the wallet
+func (a *LastTxAnchor) Anchor(ctx context.Context, address string) (string, error) {
+	return a.Client.LastTransaction(ctx, address)
+}
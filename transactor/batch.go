@@ -0,0 +1,173 @@
+package transactor
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/tx"
+)
+
+// BatchOption configures SendBatch and SendStream
+type BatchOption func(*batchOptions)
+
+type batchOptions struct {
+	concurrency int
+	retries     int
+	retryDelay  time.Duration
+}
+
+// WithConcurrency sets the number of transactions sent at the same time, 4 by default
+func WithConcurrency(n int) BatchOption {
+	return func(o *batchOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithRetries retries the transactions failing with a retryable error up to n
+// times, waiting delay before the first retry and doubling it on every retry.
+// Transactions are not retried by default
+func WithRetries(n int, delay time.Duration) BatchOption {
+	return func(o *batchOptions) {
+		o.retries = n
+		o.retryDelay = delay
+	}
+}
+
+// BatchResult is the outcome of sending one transaction of a batch
+type BatchResult struct {
+	Index    int // Position of the transaction in the batch, or in the order received from the channel
+	Tx       *tx.Transaction
+	Response string // Response of the node when the transaction was sent
+	Attempts int
+	Err      error // Error of the last attempt, nil if the transaction was sent
+}
+
+// BatchReport sums up the sending of a batch
+type BatchReport struct {
+	Results  []*BatchResult // In the order of the transactions in the batch
+	Sent     int
+	Failed   int
+	Retries  int // Attempts made after the first one, over all transactions
+	Duration time.Duration
+}
+
+// SendBatch sends signed transactions concurrently with SendTransaction and
+// reports how each one went. It returns once every transaction was sent or
+// failed, an error on one of them not stopping the others
+func (tr *Transactor) SendBatch(ctx context.Context, txs []*tx.Transaction, opts ...BatchOption) *BatchReport {
+	start := time.Now()
+	in := make(chan *tx.Transaction, len(txs))
+	for _, t := range txs {
+		in <- t
+	}
+	close(in)
+
+	report := &BatchReport{Results: make([]*BatchResult, len(txs))}
+	for result := range tr.SendStream(ctx, in, opts...) {
+		report.Results[result.Index] = result
+		if result.Err != nil {
+			report.Failed++
+		} else {
+			report.Sent++
+		}
+		report.Retries += result.Attempts - 1
+	}
+	report.Duration = time.Since(start)
+	tr.logger().Info("batch sent", "sent", report.Sent, "failed", report.Failed, "retries", report.Retries, "duration", report.Duration)
+	return report
+}
+
+// SendStream sends the signed transactions received from txs concurrently, see
+// SendBatch. The results are sent in the order the transactions complete, and
+// the returned channel is closed once txs is closed and all of them completed.
+// Once ctx is done, the transactions left are failed with its error
+func (tr *Transactor) SendStream(ctx context.Context, txs <-chan *tx.Transaction, opts ...BatchOption) <-chan *BatchResult {
+	o := &batchOptions{
+		concurrency: 4,
+		retryDelay:  time.Second,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.concurrency < 1 {
+		o.concurrency = 1
+	}
+
+	type job struct {
+		index int
+		tx    *tx.Transaction
+	}
+	jobs := make(chan job)
+	results := make(chan *BatchResult)
+	go func() {
+		defer close(jobs)
+		index := 0
+		for t := range txs {
+			jobs <- job{index: index, tx: t}
+			index++
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < o.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				results <- tr.sendWithRetries(ctx, j.index, j.tx, o)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+func (tr *Transactor) sendWithRetries(ctx context.Context, index int, t *tx.Transaction, o *batchOptions) *BatchResult {
+	result := &BatchResult{Index: index, Tx: t}
+	delay := o.retryDelay
+	for {
+		result.Attempts++
+		if err := ctx.Err(); err != nil {
+			result.Err = err
+			return result
+		}
+		result.Response, result.Err = tr.SendTransaction(ctx, t)
+		if result.Err == nil || result.Attempts > o.retries || !IsRetryable(result.Err) {
+			return result
+		}
+		tr.logger().Warn("retrying transaction", "tx", t.Hash(), "attempt", result.Attempts, "delay", delay, "error", result.Err)
+		select {
+		case <-ctx.Done():
+			result.Err = ctx.Err()
+			return result
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// IsRetryable returns true if the request may succeed when tried again: the node
+// is overloaded or failed (429 and 5xx status codes) or the connection failed
+func IsRetryable(err error) bool {
+	switch e := err.(type) {
+	case *api.HTTPError:
+		return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+	case *url.Error:
+		if e.Err == context.Canceled || e.Err == context.DeadlineExceeded {
+			return false
+		}
+		return true
+	case net.Error:
+		return true
+	}
+	return err == io.EOF || err == io.ErrUnexpectedEOF
+}
@@ -0,0 +1,313 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/tx"
+	"github.com/Dev43/arweave-go/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxTagsSize is the maximum size of the serialized tags of a transaction
+const maxTagsSize = 2048
+
+// TransactionBuilder creates unsigned transactions option by option. Options are
+// only validated together when calling Build
+type TransactionBuilder struct {
+	tr          *Transactor
+	w           arweave.WalletSigner
+	target      string
+	quantity    arweave.Amount
+	data        []byte
+	dataReader  io.Reader
+	dataFile    string
+	dataSources int
+	tags        []tx.Tag
+	contentType string
+	anchor      string
+	reward      *arweave.Amount
+	format      int
+	bundle      bool
+}
+
+// NewTransactionBuilder starts building a transaction owned by the wallet
+//
+//	txn, err := ar.NewTransactionBuilder(w).
+//		Data(data).
+//		ContentType("text/html").
+//		Tag("App-Name", "my-app").
+//		Format(2).
+//		Build(ctx)
+func (tr *Transactor) NewTransactionBuilder(w arweave.WalletSigner) *TransactionBuilder {
+	return &TransactionBuilder{tr: tr, w: w}
+}
+
+// Target sets the address receiving the quantity of the transaction
+func (b *TransactionBuilder) Target(address string) *TransactionBuilder {
+	b.target = address
+	return b
+}
+
+// Quantity sets the amount transferred to the target
+func (b *TransactionBuilder) Quantity(amount arweave.Amount) *TransactionBuilder {
+	b.quantity = amount
+	return b
+}
+
+// Data sets the data stored by the transaction
+func (b *TransactionBuilder) Data(data []byte) *TransactionBuilder {
+	b.data = data
+	b.dataSources++
+	return b
+}
+
+// DataFromReader reads the data stored by the transaction from r when building it
+func (b *TransactionBuilder) DataFromReader(r io.Reader) *TransactionBuilder {
+	b.dataReader = r
+	b.dataSources++
+	return b
+}
+
+// DataFromFile reads the data stored by the transaction from a file when building it
+func (b *TransactionBuilder) DataFromFile(path string) *TransactionBuilder {
+	b.dataFile = path
+	b.dataSources++
+	return b
+}
+
+// Tag adds a tag to the transaction
+func (b *TransactionBuilder) Tag(name string, value string) *TransactionBuilder {
+	b.tags = append(b.tags, tx.Tag{Name: name, Value: value})
+	return b
+}
+
+// ContentType sets the Content-Type tag, the MIME type gateways serve the data with.
+// A Content-Type tag with the same value is only added once, the build fails if
+// its value differs
+func (b *TransactionBuilder) ContentType(mimeType string) *TransactionBuilder {
+	b.contentType = mimeType
+	return b
+}
+
+// Anchor sets the anchor (last_tx) of the transaction instead of getting one from
+// the transactor. It can be a recent block hash or the last transaction of the wallet
+func (b *TransactionBuilder) Anchor(anchor string) *TransactionBuilder {
+	b.anchor = anchor
+	return b
+}
+
+// Reward sets the reward of the transaction instead of estimating it
+func (b *TransactionBuilder) Reward(reward arweave.Amount) *TransactionBuilder {
+	b.reward = &reward
+	return b
+}
+
+// Format sets the format of the transaction, 1 (the default) or 2
+func (b *TransactionBuilder) Format(format int) *TransactionBuilder {
+	b.format = format
+	return b
+}
+
+// Bundle marks the data as an ANS-104 bundle of data items, serialized in the
+// binary format. Bundles are format 2 transactions
+func (b *TransactionBuilder) Bundle() *TransactionBuilder {
+	b.bundle = true
+	return b
+}
+
+// Build validates the options, reads the data, requests the anchor and the
+// reward if they were not given, and returns the unsigned transaction
+func (b *TransactionBuilder) Build(ctx context.Context) (txn *tx.Transaction, err error) {
+	ctx, span := b.tr.tracer().Start(ctx, "BuildTransaction", trace.WithAttributes(
+		attribute.String("arweave.target", b.target),
+	))
+	defer func() { endSpan(span, err) }()
+
+	err = b.validate()
+	if err != nil {
+		return nil, err
+	}
+	data, err := b.readData()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 && b.quantity.IsZero() {
+		return nil, errors.New("transaction has neither data nor quantity")
+	}
+	if b.bundle {
+		err = validateBundle(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	format := b.format
+	if format == 0 {
+		format = 1
+		if b.bundle {
+			format = 2
+		}
+	}
+
+	anchor := b.anchor
+	if anchor == "" {
+		anchor, err = b.tr.anchor(ctx, b.w.Address())
+		if err != nil {
+			return nil, err
+		}
+	}
+	var reward arweave.Amount
+	if b.reward != nil {
+		reward = *b.reward
+	} else {
+		fee, err := b.tr.EstimateFee(ctx, int64(len(data)), b.target)
+		if err != nil {
+			return nil, err
+		}
+		reward = fee.Total
+	}
+
+	txn = tx.NewTransaction(anchor, b.w.PubKeyModulus(), b.quantity, b.target, data, reward)
+	for _, tag := range b.allTags() {
+		err = txn.AddTag(tag.Name, tag.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = txn.SetFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	b.tr.logger().Debug("transaction built", "owner", b.w.Address(), "format", format, "target", b.target,
+		"quantity", b.quantity.String(), "reward", reward.String(), "data_size", len(data))
+	return txn, nil
+}
+
+// validate checks that the options can be combined
+func (b *TransactionBuilder) validate() error {
+	if b.format != 0 && b.format != 1 && b.format != 2 {
+		return fmt.Errorf("unsupported transaction format %d", b.format)
+	}
+	if b.dataSources > 1 {
+		return errors.New("only one data source can be set")
+	}
+	if b.quantity.Sign() < 0 {
+		return errors.New("quantity cannot be negative")
+	}
+	if b.reward != nil && b.reward.Sign() <= 0 {
+		return errors.New("reward must be positive")
+	}
+	if !b.quantity.IsZero() && b.target == "" {
+		return errors.New("a target is needed to transfer a quantity")
+	}
+	if b.target != "" {
+		if !isHash(b.target, 32) {
+			return fmt.Errorf("invalid target address %s", b.target)
+		}
+		if b.target == b.w.Address() {
+			return errors.New("a wallet cannot send a transaction to itself")
+		}
+	}
+	if b.anchor != "" && !isHash(b.anchor, 32) && !isHash(b.anchor, 48) {
+		return fmt.Errorf("invalid anchor %s", b.anchor)
+	}
+	if b.bundle && b.format == 1 {
+		return errors.New("bundles must be format 2 transactions")
+	}
+
+	size := 0
+	for _, tag := range b.allTags() {
+		if tag.Name == "" {
+			return errors.New("tag names cannot be empty")
+		}
+		if b.contentType != "" && tag.Name == "Content-Type" && tag.Value != b.contentType {
+			return errors.New("content type set both as an option and as a tag")
+		}
+		// tags are serialized with their name and value sizes on 2 bytes each
+		size += 4 + len(tag.Name) + len(tag.Value)
+	}
+	if size > maxTagsSize {
+		return fmt.Errorf("tags take %d bytes, more than the %d allowed", size, maxTagsSize)
+	}
+	return nil
+}
+
+// allTags returns the tags set directly and the ones derived from the options,
+// which are not added again if they were set directly
+func (b *TransactionBuilder) allTags() []tx.Tag {
+	tags := append([]tx.Tag{}, b.tags...)
+	derived := []tx.Tag{}
+	if b.contentType != "" {
+		derived = append(derived, tx.Tag{Name: "Content-Type", Value: b.contentType})
+	}
+	if b.bundle {
+		derived = append(derived, tx.Tag{Name: "Bundle-Format", Value: "binary"}, tx.Tag{Name: "Bundle-Version", Value: "2.0.0"})
+	}
+	for _, tag := range derived {
+		if !hasTag(b.tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func hasTag(tags []tx.Tag, tag tx.Tag) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *TransactionBuilder) readData() ([]byte, error) {
+	switch {
+	case b.dataReader != nil:
+		return ioutil.ReadAll(b.dataReader)
+	case b.dataFile != "":
+		return ioutil.ReadFile(b.dataFile)
+	}
+	return b.data, nil
+}
+
+// isHash returns true if s is the base64url encoding of size bytes
+func isHash(s string, size int) bool {
+	b, err := utils.DecodeString(s)
+	return err == nil && len(b) == size
+}
+
+// validateBundle checks the header of an ANS-104 binary bundle: the number of data
+// items, followed by the size and ID of each item, all on 32 bytes little endian
+func validateBundle(data []byte) error {
+	if len(data) < 32 {
+		return errors.New("bundle is too short")
+	}
+	count := littleEndian(data[:32])
+	if !count.IsInt64() || count.Int64() > int64(len(data)-32)/64 {
+		return errors.New("bundle header is truncated")
+	}
+	headerSize := 32 + 64*count.Int64()
+	total := new(big.Int)
+	for i := int64(0); i < count.Int64(); i++ {
+		total.Add(total, littleEndian(data[32+64*i:64+64*i]))
+	}
+	if total.Cmp(big.NewInt(int64(len(data))-headerSize)) != 0 {
+		return errors.New("bundle item sizes do not match the bundle size")
+	}
+	return nil
+}
+
+func littleEndian(b []byte) *big.Int {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return new(big.Int).SetBytes(reversed)
+}
@@ -0,0 +1,106 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Dev43/arweave-go/tx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ChunkCaller is implemented by the clients able to upload the data of format 2
+// transactions in chunks, such as api.Client
+type ChunkCaller interface {
+	PostChunk(ctx context.Context, chunk *tx.Chunk) error
+}
+
+// UploadTransaction sends a signed transaction. The header of a format 2
+// transaction is sent without its data, which is then uploaded in chunks with
+// UploadChunks. Other transactions are sent whole with SendTransaction
+func (tr *Transactor) UploadTransaction(ctx context.Context, t *tx.Transaction, opts ...BatchOption) (resp string, err error) {
+	ctx, span := tr.tracer().Start(ctx, "UploadTransaction", trace.WithAttributes(
+		attribute.String("arweave.tx", t.Hash()),
+		attribute.Int64("arweave.data_size", t.DataSize()),
+	))
+	defer func() { endSpan(span, err) }()
+
+	if t.Format() != 2 || t.DataSize() == 0 {
+		return tr.SendTransaction(ctx, t)
+	}
+	resp, err = tr.SendTransaction(ctx, t.Header())
+	if err != nil {
+		return "", err
+	}
+	err = tr.UploadChunks(ctx, t, opts...)
+	if err != nil {
+		return "", err
+	}
+	return resp, nil
+}
+
+// UploadChunks uploads the data of a format 2 transaction whose header was sent,
+// one chunk after the other. Of the batch options, only WithRetries applies: the
+// chunks failing with a retryable error are retried, the upload stops at the
+// first chunk failing otherwise
+func (tr *Transactor) UploadChunks(ctx context.Context, t *tx.Transaction, opts ...BatchOption) (err error) {
+	ctx, span := tr.tracer().Start(ctx, "UploadChunks", trace.WithAttributes(
+		attribute.String("arweave.tx", t.Hash()),
+	))
+	defer func() { endSpan(span, err) }()
+
+	caller, ok := tr.Client.(ChunkCaller)
+	if !ok {
+		return errors.New("the client cannot upload chunks")
+	}
+	chunks, err := t.Chunks()
+	if err != nil {
+		return err
+	}
+	span.SetAttributes(attribute.Int("arweave.chunks", len(chunks)))
+	o := &batchOptions{retryDelay: time.Second}
+	for _, opt := range opts {
+		opt(o)
+	}
+	for _, c := range chunks {
+		err = tr.uploadChunk(ctx, caller, t, c, o)
+		if err != nil {
+			return err
+		}
+	}
+	tr.logger().Info("chunks uploaded", "tx", t.Hash(), "chunks", len(chunks), "size", t.DataSize())
+	return nil
+}
+
+func (tr *Transactor) uploadChunk(ctx context.Context, caller ChunkCaller, t *tx.Transaction, c *tx.Chunk, o *batchOptions) (err error) {
+	ctx, span := tr.tracer().Start(ctx, "UploadChunk", trace.WithAttributes(
+		attribute.Int64("arweave.chunk_offset", c.Offset),
+		attribute.Int("arweave.chunk_size", len(c.Data)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	delay := o.retryDelay
+	for attempt := 1; ; attempt++ {
+		span.SetAttributes(attribute.Int("arweave.attempts", attempt))
+		err = caller.PostChunk(ctx, c)
+		if err == nil {
+			tr.logger().Debug("chunk uploaded", "tx", t.Hash(), "offset", c.Offset, "size", len(c.Data), "attempts", attempt)
+			return nil
+		}
+		if attempt > o.retries || !IsRetryable(err) {
+			tr.logger().Error("uploading chunk failed", "tx", t.Hash(), "offset", c.Offset, "attempts", attempt, "error", err)
+			return err
+		}
+		tr.logger().Warn("retrying chunk", "tx", t.Hash(), "offset", c.Offset, "attempt", attempt, "delay", delay, "error", err)
+		if tr.Metrics != nil {
+			tr.Metrics.AddChunkRetries(1)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
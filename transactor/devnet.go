@@ -0,0 +1,61 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/tx"
+)
+
+// DevnetCaller is implemented by clients of development nodes such as arlocal,
+// which can mint tokens and mine blocks on demand. api.Client implements it
+type DevnetCaller interface {
+	Mint(ctx context.Context, address string, amount arweave.Amount) (arweave.Amount, error)
+	Mine(ctx context.Context, blocks int) error
+}
+
+// errNotDevnet is returned when AutoMint or AutoMine are set with a client which
+// cannot mint or mine
+var errNotDevnet = errors.New("client does not implement DevnetCaller")
+
+// devnet returns the client as a DevnetCaller
+func (tr *Transactor) devnet() (DevnetCaller, error) {
+	d, ok := tr.Client.(DevnetCaller)
+	if !ok {
+		return nil, errNotDevnet
+	}
+	return d, nil
+}
+
+// mintFunds credits the owner of the transaction with what it misses to pay for it
+func (tr *Transactor) mintFunds(ctx context.Context, txn *tx.Transaction) error {
+	d, err := tr.devnet()
+	if err != nil {
+		return err
+	}
+	address := txn.OwnerAddress()
+	balance, err := tr.Client.GetBalance(ctx, address)
+	if err != nil {
+		return err
+	}
+	missing := txn.Reward().Add(txn.Quantity()).Sub(balance)
+	if missing.Sign() <= 0 {
+		return nil
+	}
+	_, err = d.Mint(ctx, address, missing)
+	if err != nil {
+		return err
+	}
+	tr.logger().Debug("funds minted", "address", address, "amount", missing.String())
+	return nil
+}
+
+// mineBlock mines a block including the transactions just sent
+func (tr *Transactor) mineBlock(ctx context.Context) error {
+	d, err := tr.devnet()
+	if err != nil {
+		return err
+	}
+	return d.Mine(ctx, 1)
+}
@@ -0,0 +1,45 @@
+package transactor
+
+import (
+	"context"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/tx"
+)
+
+// FeeEstimate is the breakdown of the reward of a transaction
+type FeeEstimate struct {
+	Size      int64          // Number of data bytes the transaction is charged for
+	Base      arweave.Amount // Price of storing the data
+	TargetFee arweave.Amount // Fee for transferring to a wallet the network does not know yet
+	Margin    arweave.Amount // Safety margin added on top, see Transactor.FeeMarginPercent
+	Total     arweave.Amount // Reward to set on the transaction
+}
+
+// EstimateFee estimates the reward of a transaction storing size bytes of data
+// and sent to target, which can be empty
+func (tr *Transactor) EstimateFee(ctx context.Context, size int64, target string) (*FeeEstimate, error) {
+	base, err := tr.Client.GetPrice(ctx, size, "")
+	if err != nil {
+		return nil, err
+	}
+	estimate := &FeeEstimate{Size: size, Base: base}
+	if target != "" {
+		withTarget, err := tr.Client.GetPrice(ctx, size, target)
+		if err != nil {
+			return nil, err
+		}
+		estimate.TargetFee = withTarget.Sub(base)
+	}
+	subtotal := estimate.Base.Add(estimate.TargetFee)
+	estimate.Margin = subtotal.Mul(tr.FeeMarginPercent).Div(100)
+	estimate.Total = subtotal.Add(estimate.Margin)
+	return estimate, nil
+}
+
+// EstimateTransactionFee estimates the reward of the transaction. Transactions are
+// charged for their data size, which for format 2 transactions is their
+// data_size field whether or not the data is attached
+func (tr *Transactor) EstimateTransactionFee(ctx context.Context, txn *tx.Transaction) (*FeeEstimate, error) {
+	return tr.EstimateFee(ctx, txn.DataSize(), txn.Target())
+}
@@ -0,0 +1,60 @@
+package transactor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/tx"
+)
+
+// Quote is the total cost of a prospective transaction
+type Quote struct {
+	Fee      *FeeEstimate
+	Quantity arweave.Amount
+	Total    arweave.Amount // Reward and quantity, what leaves the wallet
+}
+
+// InsufficientFundsError is returned when a wallet cannot pay for a transaction
+type InsufficientFundsError struct {
+	Address  string
+	Balance  arweave.Amount
+	Required arweave.Amount
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf("insufficient funds in %s: balance is %s AR, %s AR required", e.Address, e.Balance.AR(), e.Required.AR())
+}
+
+// Quote estimates the total cost of a transaction sending amount to target, which
+// can be empty, and storing size bytes of data
+func (tr *Transactor) Quote(ctx context.Context, amount arweave.Amount, size int64, target string) (*Quote, error) {
+	fee, err := tr.EstimateFee(ctx, size, target)
+	if err != nil {
+		return nil, err
+	}
+	return &Quote{
+		Fee:      fee,
+		Quantity: amount,
+		Total:    fee.Total.Add(amount),
+	}, nil
+}
+
+// CheckFunds verifies that the balance of the address covers the required amount,
+// and returns an *InsufficientFundsError if it does not
+func (tr *Transactor) CheckFunds(ctx context.Context, address string, required arweave.Amount) error {
+	balance, err := tr.Client.GetBalance(ctx, address)
+	if err != nil {
+		return err
+	}
+	if balance.Cmp(required) < 0 {
+		return &InsufficientFundsError{Address: address, Balance: balance, Required: required}
+	}
+	return nil
+}
+
+// CheckTransactionFunds verifies that the owner of the transaction can pay for
+// its reward and quantity
+func (tr *Transactor) CheckTransactionFunds(ctx context.Context, txn *tx.Transaction) error {
+	return tr.CheckFunds(ctx, txn.OwnerAddress(), txn.Reward().Add(txn.Quantity()))
+}
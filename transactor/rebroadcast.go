@@ -0,0 +1,320 @@
+package transactor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/tx"
+)
+
+// Committer sends serialized transactions to a node, api.Client implements it
+type Committer interface {
+	Commit(ctx context.Context, data []byte) (string, error)
+}
+
+// RebroadcastEventType is the kind of action taken by the rebroadcaster
+type RebroadcastEventType string
+
+const (
+	// RebroadcastPosted is sent when a transaction still not mined is posted again
+	RebroadcastPosted RebroadcastEventType = "posted"
+	// RebroadcastResigned is sent when a transaction whose anchor expired is replaced
+	// by a new one, given in the event, which is tracked instead
+	RebroadcastResigned RebroadcastEventType = "resigned"
+	// RebroadcastMined is sent when a transaction is mined, it is not tracked anymore
+	RebroadcastMined RebroadcastEventType = "mined"
+	// RebroadcastExpired is sent when the anchor of a transaction expired and it
+	// cannot be re-signed, it is not tracked anymore
+	RebroadcastExpired RebroadcastEventType = "expired"
+	// RebroadcastError is sent when checking or posting a transaction failed, it
+	// is checked again later
+	RebroadcastError RebroadcastEventType = "error"
+)
+
+// RebroadcastEvent reports an action of the rebroadcaster
+type RebroadcastEvent struct {
+	Type        RebroadcastEventType
+	TxID        string
+	Replacement *tx.Transaction // Set on RebroadcastResigned
+	Err         error           // Set on RebroadcastError and RebroadcastExpired
+}
+
+// RebroadcastOption configures a Rebroadcaster
+type RebroadcastOption func(*Rebroadcaster)
+
+// WithPeers posts the transactions again to the peers as well as to the
+// transactor's node
+func WithPeers(peers ...Committer) RebroadcastOption {
+	return func(r *Rebroadcaster) {
+		r.peers = append(r.peers, peers...)
+	}
+}
+
+// WithPendingTimeout sets how long a transaction can stay pending or unknown to
+// the node before it is posted again, 20 minutes by default
+func WithPendingTimeout(d time.Duration) RebroadcastOption {
+	return func(r *Rebroadcaster) {
+		r.pendingTimeout = d
+	}
+}
+
+// WithCheckInterval sets the time between two checks of the transactions by Run,
+// 1 minute by default
+func WithCheckInterval(d time.Duration) RebroadcastOption {
+	return func(r *Rebroadcaster) {
+		r.checkInterval = d
+	}
+}
+
+// WithResign replaces the transactions whose anchor expired by new ones with a
+// fresh anchor, and a reward bumped by bumpPercent over the previous one or the
+// current estimate, whichever is higher. Only the transactions tracked with
+// their wallet can be re-signed
+func WithResign(bumpPercent int64) RebroadcastOption {
+	return func(r *Rebroadcaster) {
+		r.resign = true
+		r.bumpPercent = bumpPercent
+	}
+}
+
+// WithRebroadcastEvents calls fn on every action of the rebroadcaster
+func WithRebroadcastEvents(fn func(RebroadcastEvent)) RebroadcastOption {
+	return func(r *Rebroadcaster) {
+		r.onEvent = fn
+	}
+}
+
+// Rebroadcaster keeps track of sent transactions until they are mined, posting
+// them again when they stay pending for too long or disappear from the mempool.
+// Transactions are only re-signed once the node rejects them because of their
+// anchor, so that the original and its replacement cannot both be mined
+type Rebroadcaster struct {
+	tr             *Transactor
+	peers          []Committer
+	pendingTimeout time.Duration
+	checkInterval  time.Duration
+	resign         bool
+	bumpPercent    int64
+	onEvent        func(RebroadcastEvent)
+
+	mu  sync.Mutex
+	txs map[string]*trackedTx
+}
+
+type trackedTx struct {
+	tx       *tx.Transaction
+	w        arweave.WalletSigner
+	lastPost time.Time // guarded by the mutex of the rebroadcaster
+}
+
+// NewRebroadcaster creates a rebroadcaster using the transactor's node
+func (tr *Transactor) NewRebroadcaster(opts ...RebroadcastOption) *Rebroadcaster {
+	r := &Rebroadcaster{
+		tr:             tr,
+		pendingTimeout: 20 * time.Minute,
+		checkInterval:  time.Minute,
+		txs:            map[string]*trackedTx{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Track starts tracking a transaction which was just sent. The wallet is only
+// needed to re-sign it, it can be nil
+func (r *Rebroadcaster) Track(txn *tx.Transaction, w arweave.WalletSigner) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txs[txn.Hash()] = &trackedTx{tx: txn, w: w, lastPost: time.Now()}
+}
+
+// Untrack stops tracking a transaction
+func (r *Rebroadcaster) Untrack(txID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.txs, txID)
+}
+
+// Tracked returns the IDs of the transactions tracked
+func (r *Rebroadcaster) Tracked() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.txs))
+	for id := range r.txs {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Run checks the transactions every check interval until ctx is done
+func (r *Rebroadcaster) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.checkInterval)
+	defer ticker.Stop()
+	for {
+		r.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check checks the status of every transaction tracked once, posting again or
+// re-signing the ones which need it
+func (r *Rebroadcaster) Check(ctx context.Context) {
+	r.mu.Lock()
+	tracked := make([]*trackedTx, 0, len(r.txs))
+	for _, t := range r.txs {
+		tracked = append(tracked, t)
+	}
+	r.mu.Unlock()
+
+	for _, t := range tracked {
+		if ctx.Err() != nil {
+			return
+		}
+		r.check(ctx, t)
+	}
+}
+
+func (r *Rebroadcaster) check(ctx context.Context, t *trackedTx) {
+	id := t.tx.Hash()
+	status, err := r.tr.Client.GetTransactionStatus(ctx, id)
+	if err != nil {
+		r.event(RebroadcastEvent{Type: RebroadcastError, TxID: id, Err: err})
+		return
+	}
+	switch status.State {
+	case api.StatusMined:
+		r.Untrack(id)
+		r.event(RebroadcastEvent{Type: RebroadcastMined, TxID: id})
+		return
+	case api.StatusPending, api.StatusNotFound:
+		r.mu.Lock()
+		lastPost := t.lastPost
+		r.mu.Unlock()
+		if time.Since(lastPost) < r.pendingTimeout {
+			return
+		}
+	}
+
+	err = r.post(ctx, t.tx)
+	if err == nil {
+		r.mu.Lock()
+		t.lastPost = time.Now()
+		r.mu.Unlock()
+		r.event(RebroadcastEvent{Type: RebroadcastPosted, TxID: id})
+		return
+	}
+	if !isAnchorError(err) {
+		r.event(RebroadcastEvent{Type: RebroadcastError, TxID: id, Err: err})
+		return
+	}
+	if !r.resign || t.w == nil {
+		r.Untrack(id)
+		r.event(RebroadcastEvent{Type: RebroadcastExpired, TxID: id, Err: err})
+		return
+	}
+	replacement, err := r.replace(ctx, t)
+	if err != nil {
+		r.event(RebroadcastEvent{Type: RebroadcastError, TxID: id, Err: err})
+		return
+	}
+	r.mu.Lock()
+	delete(r.txs, id)
+	r.txs[replacement.Hash()] = &trackedTx{tx: replacement, w: t.w, lastPost: time.Now()}
+	r.mu.Unlock()
+	r.event(RebroadcastEvent{Type: RebroadcastResigned, TxID: id, Replacement: replacement})
+}
+
+// post sends the transaction to the node and to the peers. Only the error of
+// the node is returned, the peers' are logged
+func (r *Rebroadcaster) post(ctx context.Context, t *tx.Transaction) error {
+	serialized, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	for _, peer := range r.peers {
+		_, err := peer.Commit(ctx, serialized)
+		if err != nil {
+			r.tr.logger().Warn("posting transaction to peer failed", "tx", t.Hash(), "error", err)
+		}
+	}
+	_, err = r.tr.Client.Commit(ctx, serialized)
+	return err
+}
+
+// replace creates, signs and sends a copy of the transaction with a fresh anchor
+// and a higher reward
+func (r *Rebroadcaster) replace(ctx context.Context, t *trackedTx) (*tx.Transaction, error) {
+	old := t.tx
+	data := old.RawData()
+	if int64(len(data)) != old.DataSize() {
+		return nil, errors.New("transaction data was not kept, it cannot be re-signed")
+	}
+	fee, err := r.tr.EstimateFee(ctx, old.DataSize(), old.Target())
+	if err != nil {
+		return nil, err
+	}
+	reward := old.Reward().Add(old.Reward().Mul(r.bumpPercent).Div(100))
+	if fee.Total.Cmp(reward) > 0 {
+		reward = fee.Total
+	}
+	tags, err := old.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	b := r.tr.NewTransactionBuilder(t.w).
+		Target(old.Target()).
+		Quantity(old.Quantity()).
+		Reward(reward).
+		Format(old.Format())
+	if len(data) > 0 {
+		b.Data(data)
+	}
+	for _, tag := range tags {
+		b.Tag(tag.Name, tag.Value)
+	}
+	replacement, err := b.Build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	replacement, err = r.tr.SignTransaction(ctx, t.w, replacement)
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.tr.SendTransaction(ctx, replacement)
+	if err != nil {
+		return nil, err
+	}
+	r.tr.logger().Info("transaction re-signed", "tx", old.Hash(), "replacement", replacement.Hash(), "reward", reward.String())
+	return replacement, nil
+}
+
+func (r *Rebroadcaster) event(e RebroadcastEvent) {
+	if e.Type == RebroadcastError {
+		r.tr.logger().Warn("rebroadcasting transaction failed", "tx", e.TxID, "error", e.Err)
+	} else {
+		r.tr.logger().Debug("rebroadcaster", "event", string(e.Type), "tx", e.TxID)
+	}
+	if r.onEvent != nil {
+		r.onEvent(e)
+	}
+}
+
+// isAnchorError returns true if the node rejected the transaction because its
+// anchor is not a recent block nor the last transaction of the wallet anymore
+func isAnchorError(err error) bool {
+	httpErr, ok := err.(*api.HTTPError)
+	return ok && httpErr.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(httpErr.Body), "anchor")
+}
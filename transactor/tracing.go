@@ -0,0 +1,27 @@
+package transactor
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer used by the transactor
+const instrumentationName = "github.com/Dev43/arweave-go/transactor"
+
+// tracer returns the tracer of the transactor's provider, or of the global one if none is set
+func (tr *Transactor) tracer() trace.Tracer {
+	if tr.TracerProvider == nil {
+		return otel.GetTracerProvider().Tracer(instrumentationName)
+	}
+	return tr.TracerProvider.Tracer(instrumentationName)
+}
+
+// endSpan records the error, if any, and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
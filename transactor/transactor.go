@@ -6,11 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"time"
 
 	"github.com/Dev43/arweave-go"
 	"github.com/Dev43/arweave-go/api"
 	"github.com/Dev43/arweave-go/tx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // defaultPort of the arweave client
@@ -23,14 +24,42 @@ const defaultURL = "http://127.0.0.1" + ":" + defaultPort
 type ClientCaller interface {
 	TxAnchor(ctx context.Context) (string, error)
 	LastTransaction(ctx context.Context, address string) (string, error)
-	GetReward(ctx context.Context, data []byte) (string, error)
+	GetPrice(ctx context.Context, size int64, target string) (arweave.Amount, error)
 	Commit(ctx context.Context, data []byte) (string, error)
 	GetTransaction(ctx context.Context, txID string) (*tx.Transaction, error)
+	GetTransactionStatus(ctx context.Context, txID string) (*api.TransactionStatus, error)
+	GetBalance(ctx context.Context, address string) (arweave.Amount, error)
 }
 
 // Transactor type, allows one to create transactions
 type Transactor struct {
-	Client ClientCaller
+	Client  ClientCaller
+	Logger  arweave.Logger           // Optional, receives the transactions created, sent and waited for
+	Metrics arweave.MetricsCollector // Optional, receives the number of transactions waiting for confirmations and of chunks retried
+	// FeeMarginPercent is a safety margin added to the estimated rewards, 10 adds 10%
+	FeeMarginPercent int64
+	// CheckBalance makes SendTransaction verify that the owner can pay for the
+	// transaction before sending it, see CheckTransactionFunds
+	CheckBalance bool
+	// AutoMint makes SendTransaction mint the funds the owner misses to pay for the
+	// transaction, and AutoMine mine a block once it is sent. They are meant for
+	// development nodes, the client must implement DevnetCaller
+	AutoMint bool
+	AutoMine bool
+	// Anchors provides the anchors of the transactions created, a block anchor is
+	// requested from the node for each transaction if none is set
+	Anchors AnchorProvider
+	// TracerProvider is the OpenTelemetry provider of the spans of the transactor's
+	// operations. The global provider is used if none is set
+	TracerProvider trace.TracerProvider
+}
+
+// logger returns the transactor's logger, discarding everything if none is set
+func (tr *Transactor) logger() arweave.Logger {
+	if tr.Logger == nil {
+		return arweave.NopLogger{}
+	}
+	return tr.Logger
 }
 
 // NewTransactor creates a new arweave transactor. You need to pass in a context and a url
@@ -64,16 +93,23 @@ func NewTransactor(fullURL string) (*Transactor, error) {
 }
 
 // CreateTransaction creates a brand new transaction
-func (tr *Transactor) CreateTransaction(ctx context.Context, w arweave.WalletSigner, amount string, data []byte, target string) (*tx.Transaction, error) {
-	lastTx, err := tr.Client.TxAnchor(ctx)
+func (tr *Transactor) CreateTransaction(ctx context.Context, w arweave.WalletSigner, amount arweave.Amount, data []byte, target string) (txn *tx.Transaction, err error) {
+	ctx, span := tr.tracer().Start(ctx, "CreateTransaction", trace.WithAttributes(
+		attribute.String("arweave.target", target),
+		attribute.Int("arweave.data_size", len(data)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	lastTx, err := tr.anchor(ctx, w.Address())
 	if err != nil {
 		return nil, err
 	}
 
-	price, err := tr.Client.GetReward(ctx, []byte(data))
+	fee, err := tr.EstimateFee(ctx, int64(len(data)), target)
 	if err != nil {
 		return nil, err
 	}
+	price := fee.Total
 
 	// Non encoded transaction fields
 	tx := tx.NewTransaction(
@@ -84,42 +120,63 @@ func (tr *Transactor) CreateTransaction(ctx context.Context, w arweave.WalletSig
 		data,
 		price,
 	)
+	tr.logger().Debug("transaction created", "owner", w.Address(), "target", target, "quantity", amount.String(), "reward", price.String(), "data_size", len(data))
 
 	return tx, nil
 }
 
+// SignTransaction signs the transaction with the wallet, see tx.Transaction.Sign.
+// Unlike calling Sign directly, the signature is traced as part of ctx
+func (tr *Transactor) SignTransaction(ctx context.Context, w arweave.WalletSigner, txn *tx.Transaction) (signed *tx.Transaction, err error) {
+	_, span := tr.tracer().Start(ctx, "SignTransaction")
+	defer func() { endSpan(span, err) }()
+
+	signed, err = txn.Sign(w)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("arweave.tx", signed.Hash()))
+	return signed, nil
+}
+
 // SendTransaction formats the transactions (base64url encodes the necessary fields)
 // marshalls the Json and sends it to the arweave network
-func (tr *Transactor) SendTransaction(ctx context.Context, tx *tx.Transaction) (string, error) {
+func (tr *Transactor) SendTransaction(ctx context.Context, tx *tx.Transaction) (resp string, err error) {
+	ctx, span := tr.tracer().Start(ctx, "SendTransaction", trace.WithAttributes(
+		attribute.String("arweave.tx", tx.Hash()),
+	))
+	defer func() { endSpan(span, err) }()
+
 	if len(tx.Signature()) == 0 {
 		return "", errors.New("transaction missing signature")
 	}
+	if tr.AutoMint {
+		err = tr.mintFunds(ctx, tx)
+		if err != nil {
+			return "", err
+		}
+	}
+	if tr.CheckBalance {
+		err = tr.CheckTransactionFunds(ctx, tx)
+		if err != nil {
+			return "", err
+		}
+	}
 	serialized, err := json.Marshal(tx)
 	if err != nil {
 		return "", err
 	}
-	return tr.Client.Commit(ctx, serialized)
-}
-
-// WaitMined waits for the transaction to be mined
-func (tr *Transactor) WaitMined(ctx context.Context, tx *tx.Transaction) (*tx.Transaction, error) {
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-
-	for {
-		receipt, err := tr.Client.GetTransaction(ctx, tx.Hash())
-		if receipt != nil {
-			return receipt, nil
-		}
+	resp, err = tr.Client.Commit(ctx, serialized)
+	if err != nil {
+		tr.logger().Error("sending transaction failed", "tx", tx.Hash(), "error", err)
+		return "", err
+	}
+	tr.logger().Info("transaction sent", "tx", tx.Hash(), "size", len(serialized))
+	if tr.AutoMine {
+		err = tr.mineBlock(ctx)
 		if err != nil {
-			fmt.Printf("Error retrieving transaction %s \n", err.Error())
-		} else {
-			fmt.Printf("Transaction not yet mined \n")
-		}
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
+			return "", err
 		}
 	}
+	return resp, nil
 }
@@ -2,20 +2,40 @@ package transactor
 
 import (
 	"context"
+	"errors"
+	"io"
 	"math/big"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/arweavetest"
 	"github.com/Dev43/arweave-go/tx"
 	"github.com/Dev43/arweave-go/utils"
+	"github.com/Dev43/arweave-go/wallet"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var ctx = context.TODO()
 
 type mockCaller struct {
-	LastTx string
-	Reward string
-	Txn    *tx.Transaction
+	LastTx    string
+	Reward    arweave.Amount
+	TargetFee arweave.Amount // added to the reward when a target is given
+	Balance   arweave.Amount
+	Txn       *tx.Transaction
+	Statuses  []*api.TransactionStatus // returned in turn, the last one repeating
 }
 
 func (m *mockCaller) TxAnchor(ctx context.Context) (string, error) {
@@ -26,10 +46,17 @@ func (m *mockCaller) LastTransaction(ctx context.Context, address string) (strin
 	return m.LastTx, nil
 }
 
-func (m *mockCaller) GetReward(ctx context.Context, data []byte) (string, error) {
+func (m *mockCaller) GetPrice(ctx context.Context, size int64, target string) (arweave.Amount, error) {
+	if target != "" {
+		return m.Reward.Add(m.TargetFee), nil
+	}
 	return m.Reward, nil
 }
 
+func (m *mockCaller) GetBalance(ctx context.Context, address string) (arweave.Amount, error) {
+	return m.Balance, nil
+}
+
 func (m *mockCaller) Commit(ctx context.Context, data []byte) (string, error) {
 	return "TESTOK", nil
 }
@@ -38,6 +65,14 @@ func (m *mockCaller) GetTransaction(ctx context.Context, txID string) (*tx.Trans
 	return m.Txn, nil
 }
 
+func (m *mockCaller) GetTransactionStatus(ctx context.Context, txID string) (*api.TransactionStatus, error) {
+	status := m.Statuses[0]
+	if len(m.Statuses) > 1 {
+		m.Statuses = m.Statuses[1:]
+	}
+	return status, nil
+}
+
 type mockWallet struct {
 	Signature         []byte
 	TestAddress       string
@@ -65,7 +100,7 @@ func TestCreateTransaction(t *testing.T) {
 	cases := []struct {
 		caller   *mockCaller
 		wallet   *mockWallet
-		quantity string
+		quantity arweave.Amount
 		target   string
 		data     []byte
 		tag      []tx.Tag
@@ -73,14 +108,14 @@ func TestCreateTransaction(t *testing.T) {
 		{
 			&mockCaller{
 				LastTx: "0xA",
-				Reward: "1000",
+				Reward: arweave.NewWinston(1000),
 				Txn:    nil},
 			&mockWallet{
 				Signature:         nil,
 				TestAddress:       "0xB",
 				TestPubKeyModulus: big.NewInt(1),
 			},
-			"1",
+			arweave.NewWinston(1),
 			"0xC",
 			[]byte("hello"),
 			make([]tx.Tag, 0),
@@ -101,3 +136,767 @@ func TestCreateTransaction(t *testing.T) {
 	}
 
 }
+
+func TestWaitMined(t *testing.T) {
+	mined := func(block string, confirmations int64) *api.TransactionStatus {
+		return &api.TransactionStatus{State: api.StatusMined, BlockIndepHash: block, Confirmations: confirmations}
+	}
+	txn := tx.NewTransaction("", big.NewInt(1), arweave.Amount{}, "", nil, arweave.Amount{})
+	caller := &mockCaller{
+		Txn: txn,
+		Statuses: []*api.TransactionStatus{
+			{State: api.StatusNotFound},
+			{State: api.StatusPending},
+			mined("a", 1),
+			// the block got forked out, the transaction is back in the mempool
+			{State: api.StatusPending},
+			mined("b", 1),
+			mined("b", 2),
+			mined("b", 3),
+		},
+	}
+	tr := Transactor{Client: caller}
+
+	events := []WaitEventType{}
+	receipt, err := tr.WaitMined(ctx, txn,
+		WithConfirmations(3),
+		WithPollInterval(time.Millisecond),
+		WithProgress(func(e WaitEvent) {
+			events = append(events, e.Type)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, txn, receipt)
+	assert.Equal(t, []WaitEventType{
+		EventNotFound, EventPending, EventMined, EventReorged, EventPending, EventMined, EventMined, EventConfirmed,
+	}, events)
+}
+
+func TestWaitMinedDropped(t *testing.T) {
+	txn := tx.NewTransaction("", big.NewInt(1), arweave.Amount{}, "", nil, arweave.Amount{})
+	caller := &mockCaller{
+		Statuses: []*api.TransactionStatus{{State: api.StatusPending}, {State: api.StatusDropped}},
+	}
+	tr := Transactor{Client: caller}
+	_, err := tr.WaitMined(ctx, txn, WithPollInterval(time.Millisecond))
+	assert.Equal(t, ErrTransactionDropped, err)
+}
+
+func TestWaitMinedEvicted(t *testing.T) {
+	txn := tx.NewTransaction("", big.NewInt(1), arweave.Amount{}, "", nil, arweave.Amount{})
+	caller := &mockCaller{
+		Statuses: []*api.TransactionStatus{
+			// not found until the node receives it
+			{State: api.StatusNotFound},
+			{State: api.StatusNotFound},
+			{State: api.StatusPending},
+			{State: api.StatusNotFound},
+			{State: api.StatusPending},
+			{State: api.StatusNotFound},
+		},
+	}
+	tr := Transactor{Client: caller}
+	events := []WaitEventType{}
+	_, err := tr.WaitMined(ctx, txn,
+		WithPollInterval(time.Millisecond),
+		WithNotFoundLimit(3),
+		WithProgress(func(e WaitEvent) {
+			events = append(events, e.Type)
+		}),
+	)
+	assert.Equal(t, ErrTransactionDropped, err)
+	assert.Equal(t, []WaitEventType{
+		EventNotFound, EventNotFound, EventPending, EventNotFound, EventPending, EventNotFound, EventNotFound, EventDropped,
+	}, events)
+
+	// without a limit, waiting stops with ctx
+	caller.Statuses = []*api.TransactionStatus{{State: api.StatusPending}, {State: api.StatusNotFound}}
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = tr.WaitMined(waitCtx, txn, WithPollInterval(time.Millisecond), WithNotFoundLimit(0))
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestSendTransactionCheckBalance(t *testing.T) {
+	w := wallet.NewWallet()
+	err := w.LoadKeyFromFile(filepath.Join("..", "wallet", "testdata", "arweave-test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := arweavetest.NewNode()
+	defer node.Close()
+	node.SetPrice(arweave.NewWinston(100), arweave.NewWinston(0))
+	node.SetBalance(w.Address(), arweave.NewWinston(1000))
+	tr, err := NewTransactor(node.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.CheckBalance = true
+
+	txn, err := tr.NewTransactionBuilder(w).Target("1seRanklLU_1VTGkEk7P0xAwMJfA7owA1JHW5KyZKlY").Quantity(arweave.NewWinston(950)).Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn, err = tr.SignTransaction(ctx, w, txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tr.SendTransaction(ctx, txn)
+	fundsErr, ok := err.(*InsufficientFundsError)
+	if !ok {
+		t.Fatalf("expected an *InsufficientFundsError, got %v", err)
+	}
+	assert.Equal(t, w.Address(), fundsErr.Address)
+	assert.Equal(t, "1000", fundsErr.Balance.String())
+	assert.Equal(t, txn.Reward().Add(arweave.NewWinston(950)), fundsErr.Required)
+	assert.NotContains(t, node.Requests(), "POST /tx", "the transaction should not be sent")
+	assert.Empty(t, node.Pending())
+
+	node.SetBalance(w.Address(), arweave.NewWinston(1000000))
+	_, err = tr.SendTransaction(ctx, txn)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{txn.Hash()}, node.Pending())
+}
+
+func TestEstimateFee(t *testing.T) {
+	caller := &mockCaller{Reward: arweave.NewWinston(1000), TargetFee: arweave.NewWinston(500)}
+	tr := Transactor{Client: caller, FeeMarginPercent: 10}
+
+	fee, err := tr.EstimateFee(ctx, 100, "target")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1000", fee.Base.String())
+	assert.Equal(t, "500", fee.TargetFee.String())
+	assert.Equal(t, "150", fee.Margin.String())
+	assert.Equal(t, "1650", fee.Total.String())
+
+	fee, err = tr.EstimateFee(ctx, 100, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, fee.TargetFee.IsZero(), "no target fee without a target")
+	assert.Equal(t, "1100", fee.Total.String())
+}
+
+func TestCheckFunds(t *testing.T) {
+	caller := &mockCaller{Reward: arweave.NewWinston(1000), Balance: arweave.NewWinston(1500)}
+	tr := Transactor{Client: caller}
+
+	quote, err := tr.Quote(ctx, arweave.NewWinston(400), 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1400", quote.Total.String())
+	assert.NoError(t, tr.CheckFunds(ctx, "address", quote.Total))
+
+	quote, err = tr.Quote(ctx, arweave.NewWinston(600), 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tr.CheckFunds(ctx, "address", quote.Total)
+	assert.Equal(t, &InsufficientFundsError{
+		Address:  "address",
+		Balance:  arweave.NewWinston(1500),
+		Required: arweave.NewWinston(1600),
+	}, err)
+}
+
+func TestTransactionBuilder(t *testing.T) {
+	address := utils.EncodeToBase64(make([]byte, 32))
+	target := utils.EncodeToBase64([]byte("01234567890123456789012345678901"))
+	bundle := make([]byte, 32+64+5)
+	bundle[0] = 1
+	bundle[32] = 5
+
+	cases := []struct {
+		name  string
+		build func(b *TransactionBuilder) *TransactionBuilder
+		fails bool
+	}{
+		{"data", func(b *TransactionBuilder) *TransactionBuilder { return b.Data([]byte("hello")) }, false},
+		{"transfer", func(b *TransactionBuilder) *TransactionBuilder {
+			return b.Target(target).Quantity(arweave.NewWinston(1))
+		}, false},
+		{"bundle", func(b *TransactionBuilder) *TransactionBuilder { return b.Data(bundle).Bundle() }, false},
+		{"empty", func(b *TransactionBuilder) *TransactionBuilder { return b }, true},
+		{"two data sources", func(b *TransactionBuilder) *TransactionBuilder {
+			return b.Data([]byte("a")).DataFromFile("a.txt")
+		}, true},
+		{"quantity without target", func(b *TransactionBuilder) *TransactionBuilder { return b.Quantity(arweave.NewWinston(1)) }, true},
+		{"invalid target", func(b *TransactionBuilder) *TransactionBuilder {
+			return b.Target("0xC").Quantity(arweave.NewWinston(1))
+		}, true},
+		{"own address", func(b *TransactionBuilder) *TransactionBuilder {
+			return b.Target(address).Quantity(arweave.NewWinston(1))
+		}, true},
+		{"zero reward", func(b *TransactionBuilder) *TransactionBuilder { return b.Data([]byte("a")).Reward(arweave.Amount{}) }, true},
+		{"invalid anchor", func(b *TransactionBuilder) *TransactionBuilder { return b.Data([]byte("a")).Anchor("anchor") }, true},
+		{"unknown format", func(b *TransactionBuilder) *TransactionBuilder { return b.Data([]byte("a")).Format(3) }, true},
+		{"format 1 bundle", func(b *TransactionBuilder) *TransactionBuilder { return b.Data(bundle).Bundle().Format(1) }, true},
+		{"invalid bundle", func(b *TransactionBuilder) *TransactionBuilder { return b.Data([]byte("a")).Bundle() }, true},
+		{"two content types", func(b *TransactionBuilder) *TransactionBuilder {
+			return b.Data([]byte("a")).ContentType("text/plain").Tag("Content-Type", "text/html")
+		}, true},
+		{"tags too large", func(b *TransactionBuilder) *TransactionBuilder {
+			return b.Data([]byte("a")).Tag("Large", string(make([]byte, 2048)))
+		}, true},
+	}
+
+	for _, c := range cases {
+		tr := Transactor{Client: &mockCaller{LastTx: "0xA", Reward: arweave.NewWinston(1000)}}
+		w := &mockWallet{TestAddress: address, TestPubKeyModulus: big.NewInt(1)}
+		_, err := c.build(tr.NewTransactionBuilder(w)).Build(ctx)
+		if c.fails {
+			assert.Error(t, err, c.name)
+		} else {
+			assert.NoError(t, err, c.name)
+		}
+	}
+}
+
+func TestTransactionBuilderFields(t *testing.T) {
+	tr := Transactor{Client: &mockCaller{LastTx: "0xA", Reward: arweave.NewWinston(1000)}}
+	w := &mockWallet{TestAddress: "0xB", TestPubKeyModulus: big.NewInt(1)}
+	anchor := utils.EncodeToBase64(make([]byte, 32))
+
+	txn, err := tr.NewTransactionBuilder(w).
+		Data([]byte("<html></html>")).
+		ContentType("text/html").
+		Tag("App-Name", "test").
+		Anchor(anchor).
+		Reward(arweave.NewWinston(42)).
+		Format(2).
+		Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, txn.Format())
+	assert.Equal(t, anchor, txn.LastTx())
+	assert.Equal(t, "42", txn.Reward().String())
+	assert.NotEmpty(t, txn.DataRoot())
+	tags, err := txn.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []tx.Tag{{Name: "App-Name", Value: "test"}, {Name: "Content-Type", Value: "text/html"}}, tags)
+
+	// the content type is not added twice when also set as a tag
+	txn, err = tr.NewTransactionBuilder(w).
+		Data([]byte("<html></html>")).
+		Tag("Content-Type", "text/html").
+		ContentType("text/html").
+		Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err = txn.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []tx.Tag{{Name: "Content-Type", Value: "text/html"}}, tags)
+}
+
+// countingCaller counts the anchors requested
+type countingCaller struct {
+	mockCaller
+	anchors int32
+}
+
+func (m *countingCaller) TxAnchor(ctx context.Context) (string, error) {
+	atomic.AddInt32(&m.anchors, 1)
+	return m.LastTx, nil
+}
+
+func TestCachedAnchor(t *testing.T) {
+	caller := &countingCaller{mockCaller: mockCaller{LastTx: "anchor", Reward: arweave.NewWinston(1000)}}
+	tr := Transactor{Client: caller, Anchors: NewCachedAnchor(caller, time.Hour)}
+	w := &mockWallet{TestAddress: "0xB", TestPubKeyModulus: big.NewInt(1)}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			txn, err := tr.CreateTransaction(ctx, w, arweave.Amount{}, []byte("hello"), "")
+			assert.NoError(t, err)
+			assert.Equal(t, "anchor", txn.LastTx())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&caller.anchors))
+
+	expiring := NewCachedAnchor(caller, time.Nanosecond)
+	expiring.Anchor(ctx, "")
+	time.Sleep(time.Millisecond)
+	expiring.Anchor(ctx, "")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&caller.anchors))
+}
+
+// slowCaller answers the anchor requests once released
+type slowCaller struct {
+	mockCaller
+	mu      sync.Mutex
+	anchor  string
+	err     error
+	release chan struct{} // requests wait for it to be closed, if set
+}
+
+func (m *slowCaller) TxAnchor(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	release := m.release
+	m.mu.Unlock()
+	if release != nil {
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.anchor, m.err
+}
+
+func TestCachedAnchorRefresh(t *testing.T) {
+	caller := &slowCaller{anchor: "first"}
+	a := NewCachedAnchor(caller, time.Hour)
+	anchor, err := a.Refresh(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", anchor)
+
+	// the current anchor is served while a refresh waits for the node
+	caller.mu.Lock()
+	caller.anchor = "second"
+	caller.release = make(chan struct{})
+	caller.mu.Unlock()
+	refreshed := make(chan string)
+	go func() {
+		anchor, _ := a.Refresh(ctx)
+		refreshed <- anchor
+	}()
+	served := make(chan string)
+	go func() {
+		anchor, _ := a.Anchor(ctx, "")
+		served <- anchor
+	}()
+	select {
+	case anchor := <-served:
+		assert.Equal(t, "first", anchor)
+	case <-time.After(time.Second):
+		t.Fatal("Anchor waited for the refresh")
+	}
+	close(caller.release)
+	assert.Equal(t, "second", <-refreshed)
+	anchor, _ = a.Anchor(ctx, "")
+	assert.Equal(t, "second", anchor)
+
+	// a caller giving up does not fail the others waiting for the same request
+	caller.mu.Lock()
+	caller.anchor = "third"
+	caller.release = make(chan struct{})
+	caller.mu.Unlock()
+	canceled, cancel := context.WithCancel(ctx)
+	gaveUp := make(chan error)
+	go func() {
+		_, err := a.Refresh(canceled)
+		gaveUp <- err
+	}()
+	for fetching := false; !fetching; time.Sleep(time.Millisecond) {
+		a.mu.Lock()
+		fetching = a.fetching != nil
+		a.mu.Unlock()
+	}
+	go func() {
+		anchor, _ := a.Refresh(ctx)
+		refreshed <- anchor
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	assert.Equal(t, context.Canceled, <-gaveUp)
+	close(caller.release)
+	assert.Equal(t, "third", <-refreshed)
+}
+
+func TestCachedAnchorStart(t *testing.T) {
+	caller := &slowCaller{err: errors.New("unavailable")}
+	logger := &recordingLogger{}
+	a := NewCachedAnchor(caller, 2*time.Millisecond)
+	a.Logger = logger
+	startCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	assert.Equal(t, caller.err, a.Start(startCtx))
+	for i := 0; i < 100; i++ {
+		logger.mu.Lock()
+		logged := len(logger.messages)
+		logger.mu.Unlock()
+		if logged > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	logger.mu.Lock()
+	assert.Contains(t, logger.messages, "WARN refreshing anchor failed")
+	logger.mu.Unlock()
+
+	// TTLs too small for a ticker do not make Start panic
+	for _, ttl := range []time.Duration{0, -time.Second, time.Nanosecond} {
+		assert.NoError(t, NewCachedAnchor(&slowCaller{anchor: "anchor"}, ttl).Start(startCtx))
+	}
+}
+
+func TestLastTxAnchor(t *testing.T) {
+	caller := &countingCaller{mockCaller: mockCaller{LastTx: "last", Reward: arweave.NewWinston(1000)}}
+	tr := Transactor{Client: caller, Anchors: &LastTxAnchor{Client: caller}}
+	w := &mockWallet{TestAddress: "0xB", TestPubKeyModulus: big.NewInt(1)}
+
+	txn, err := tr.NewTransactionBuilder(w).Data([]byte("hello")).Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "last", txn.LastTx())
+	assert.Equal(t, int32(0), caller.anchors, "no block anchor requested")
+}
+
+// flakyCaller fails the commits with the errors given, in turn, before accepting them
+type flakyCaller struct {
+	mockCaller
+	mu     sync.Mutex
+	errors map[string][]error // by transaction ID
+}
+
+func (m *flakyCaller) Commit(ctx context.Context, data []byte) (string, error) {
+	t := tx.Transaction{}
+	if err := t.UnmarshalJSON(data); err != nil {
+		return "", err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	errs := m.errors[t.Hash()]
+	if len(errs) > 0 {
+		m.errors[t.Hash()] = errs[1:]
+		return "", errs[0]
+	}
+	return "OK", nil
+}
+
+func TestSendBatch(t *testing.T) {
+	txs := []*tx.Transaction{}
+	for i := 0; i < 5; i++ {
+		txn := tx.NewTransaction("anchor", big.NewInt(1), arweave.Amount{}, "", []byte{byte(i)}, arweave.NewWinston(1000))
+		signed, err := txn.Sign(&mockWallet{Signature: []byte{byte(i)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, signed)
+	}
+	unavailable := &api.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}
+	invalid := &api.HTTPError{StatusCode: 400, Status: "400 Bad Request"}
+	caller := &flakyCaller{errors: map[string][]error{
+		txs[1].Hash(): {unavailable, unavailable},
+		txs[2].Hash(): {invalid},
+		txs[3].Hash(): {unavailable, unavailable, unavailable},
+	}}
+	tr := Transactor{Client: caller}
+
+	report := tr.SendBatch(ctx, txs, WithConcurrency(2), WithRetries(2, time.Millisecond))
+	assert.Equal(t, 3, report.Sent)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 4, report.Retries)
+	attempts := []int{}
+	for i, r := range report.Results {
+		assert.Equal(t, txs[i], r.Tx)
+		attempts = append(attempts, r.Attempts)
+	}
+	assert.Equal(t, []int{1, 3, 1, 3, 1}, attempts)
+	assert.Equal(t, invalid, report.Results[2].Err)
+	assert.Equal(t, unavailable, report.Results[3].Err)
+	assert.Equal(t, "OK", report.Results[1].Response)
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err       error
+		retryable bool
+	}{
+		{&api.HTTPError{StatusCode: 429}, true},
+		{&api.HTTPError{StatusCode: 502}, true},
+		{&api.HTTPError{StatusCode: 400}, false},
+		{&url.Error{Op: "Post", URL: "http://node", Err: io.ErrUnexpectedEOF}, true},
+		{&url.Error{Op: "Post", URL: "http://node", Err: context.Canceled}, false},
+		{errors.New("transaction missing signature"), false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.retryable, IsRetryable(c.err), c.err.Error())
+	}
+}
+
+func TestRebroadcaster(t *testing.T) {
+	w := wallet.NewWallet()
+	err := w.LoadKeyFromFile(filepath.Join("..", "wallet", "testdata", "arweave-test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := arweavetest.NewNode()
+	defer node.Close()
+	peer := arweavetest.NewNode()
+	defer peer.Close()
+	node.SetBalance(w.Address(), arweave.NewWinston(1000000000))
+	tr, err := NewTransactor(node.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	peerClient, err := api.Dial(peer.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := []RebroadcastEvent{}
+	r := tr.NewRebroadcaster(
+		WithPeers(peerClient),
+		WithPendingTimeout(time.Hour),
+		WithResign(10),
+		WithRebroadcastEvents(func(e RebroadcastEvent) { events = append(events, e) }),
+	)
+	txn, err := tr.NewTransactionBuilder(w).Data([]byte("hello")).Tag("App-Name", "test").Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn, err = tr.SignTransaction(ctx, w, txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tr.SendTransaction(ctx, txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Track(txn, w)
+
+	// pending transactions are left alone until the timeout
+	r.Check(ctx)
+	assert.Empty(t, events)
+
+	// dropped transactions are posted again, to the peers as well
+	node.Drop(txn.Hash(), "Evicted.")
+	r.Check(ctx)
+	assert.Equal(t, RebroadcastPosted, events[0].Type)
+	assert.Equal(t, []string{txn.Hash()}, node.Pending())
+	assert.Contains(t, peer.Requests(), "POST /tx")
+
+	// once the anchor expired they are re-signed
+	node.Drop(txn.Hash(), "Evicted.")
+	node.Mine(51)
+	r.Check(ctx)
+	assert.Equal(t, RebroadcastResigned, events[1].Type)
+	replacement := events[1].Replacement
+	assert.Equal(t, []string{replacement.Hash()}, node.Pending())
+	assert.True(t, replacement.Reward().Cmp(txn.Reward().Mul(110).Div(100)) >= 0, "reward is bumped")
+	assert.Equal(t, txn.RawTags(), replacement.RawTags())
+	assert.Equal(t, []string{replacement.Hash()}, r.Tracked())
+
+	node.Mine(1)
+	r.Check(ctx)
+	assert.Equal(t, RebroadcastMined, events[2].Type)
+	assert.Empty(t, r.Tracked())
+}
+
+// recordingLogger keeps the messages logged, with their level
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) record(level string, msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, level+" "+msg)
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) { l.record("DEBUG", msg) }
+func (l *recordingLogger) Info(msg string, args ...interface{})  { l.record("INFO", msg) }
+func (l *recordingLogger) Warn(msg string, args ...interface{})  { l.record("WARN", msg) }
+func (l *recordingLogger) Error(msg string, args ...interface{}) { l.record("ERROR", msg) }
+
+// chunkMetrics counts the chunk retries, ignoring the other measurements
+type chunkMetrics struct {
+	retries int
+}
+
+func (m *chunkMetrics) ObserveRequest(node string, endpoint string, status int, duration time.Duration) {
+}
+func (m *chunkMetrics) AddBytesUploaded(node string, endpoint string, bytes int) {}
+func (m *chunkMetrics) AddPendingConfirmations(delta int)                        {}
+func (m *chunkMetrics) AddChunkRetries(count int)                                { m.retries += count }
+
+func TestUploadTransaction(t *testing.T) {
+	w := wallet.NewWallet()
+	err := w.LoadKeyFromFile(filepath.Join("..", "wallet", "testdata", "arweave-test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := arweavetest.NewNode()
+	defer node.Close()
+	node.SetPrice(arweave.NewWinston(1), arweave.NewWinston(1))
+	node.SetBalance(w.Address(), arweave.NewWinston(1000000000))
+	tr, err := NewTransactor(node.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := &recordingLogger{}
+	tr.Logger = logger
+	metrics := &chunkMetrics{}
+	tr.Metrics = metrics
+
+	data := make([]byte, 2*tx.MaxChunkSize+1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	txn, err := tr.NewTransactionBuilder(w).Data(data).Format(2).Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn, err = tr.SignTransaction(ctx, w, txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.AddFault(arweavetest.Fault{Method: "POST", Path: "chunk", Times: 2, Status: 503})
+	_, err = tr.UploadTransaction(ctx, txn, WithRetries(2, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.Mine(1)
+	c := tr.Client.(*api.Client)
+	uploaded, err := c.GetData(ctx, txn.Hash())
+	assert.NoError(t, err)
+	assert.Equal(t, utils.EncodeToBase64(data), uploaded)
+	assert.Empty(t, node.Transaction(txn.Hash()).RawData(), "the header is sent without the data")
+
+	assert.Equal(t, []string{
+		"INFO transaction sent",
+		"WARN retrying chunk",
+		"WARN retrying chunk",
+		"DEBUG chunk uploaded",
+		"DEBUG chunk uploaded",
+		"DEBUG chunk uploaded",
+		"INFO chunks uploaded",
+	}, logger.messages[len(logger.messages)-7:])
+	assert.Equal(t, 2, metrics.retries)
+
+	// chunks failing otherwise are not retried
+	logger.messages = nil
+	node.AddFault(arweavetest.Fault{Method: "POST", Path: "chunk", Status: 400})
+	err = tr.UploadChunks(ctx, txn, WithRetries(2, time.Millisecond))
+	assert.Equal(t, 400, err.(*api.HTTPError).StatusCode)
+	assert.Equal(t, []string{"ERROR uploading chunk failed"}, logger.messages)
+}
+
+func TestUploadTracing(t *testing.T) {
+	w := wallet.NewWallet()
+	err := w.LoadKeyFromFile(filepath.Join("..", "wallet", "testdata", "arweave-test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := arweavetest.NewNode()
+	defer node.Close()
+	node.SetPrice(arweave.NewWinston(1), arweave.NewWinston(1))
+	node.SetBalance(w.Address(), arweave.NewWinston(1000000000))
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer otel.SetTextMapPropagator(otel.GetTextMapPropagator())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	c, err := api.Dial(node.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetTracerProvider(tp)
+	var mu sync.Mutex
+	traceparents := []string{}
+	c.Use(func(next api.Handler) api.Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/chunk" {
+				mu.Lock()
+				traceparents = append(traceparents, req.Header.Get("traceparent"))
+				mu.Unlock()
+			}
+			return next(req)
+		}
+	})
+	tr := &Transactor{Client: c, TracerProvider: tp}
+
+	txn, err := tr.NewTransactionBuilder(w).Data(make([]byte, tx.MaxChunkSize+tx.MinChunkSize)).Format(2).Build(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn, err = tr.SignTransaction(ctx, w, txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tr.UploadTransaction(ctx, txn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := map[string][]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		byName[s.Name()] = append(byName[s.Name()], s)
+	}
+	upload := byName["UploadTransaction"][0]
+	assert.False(t, upload.Parent().IsValid(), "the upload is the root span")
+	assert.Equal(t, upload.SpanContext().SpanID(), byName["SendTransaction"][0].Parent().SpanID())
+	chunks := byName["UploadChunks"][0]
+	assert.Equal(t, upload.SpanContext().SpanID(), chunks.Parent().SpanID())
+
+	if assert.Len(t, byName["UploadChunk"], 2) && assert.Len(t, byName["HTTP POST chunk"], 2) {
+		chunkSpans := map[trace.SpanID]bool{}
+		for _, s := range byName["UploadChunk"] {
+			assert.Equal(t, chunks.SpanContext().SpanID(), s.Parent().SpanID())
+			chunkSpans[s.SpanContext().SpanID()] = true
+		}
+		for i, s := range byName["HTTP POST chunk"] {
+			assert.True(t, chunkSpans[s.Parent().SpanID()], "the requests are children of the chunk spans")
+			assert.Equal(t, upload.SpanContext().TraceID(), s.SpanContext().TraceID())
+			// the trace context is sent to the node
+			assert.Contains(t, traceparents[i], s.SpanContext().SpanID().String())
+		}
+	}
+}
+
+func TestRebroadcasterConcurrentTrack(t *testing.T) {
+	caller := &mockCaller{Statuses: []*api.TransactionStatus{{State: api.StatusPending}}}
+	tr := Transactor{Client: caller}
+	r := tr.NewRebroadcaster(WithPendingTimeout(0))
+	txs := []*tx.Transaction{}
+	for i := 0; i < 20; i++ {
+		txn := tx.NewTransaction("anchor", big.NewInt(1), arweave.Amount{}, "", []byte{byte(i)}, arweave.NewWinston(1000))
+		signed, err := txn.Sign(&mockWallet{Signature: []byte{byte(i)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, signed)
+	}
+
+	// run with -race: tracking while checks run, from Run and from other
+	// goroutines, must not race on the tracked transactions
+	wg := sync.WaitGroup{}
+	for _, txn := range txs {
+		wg.Add(2)
+		go func(txn *tx.Transaction) {
+			defer wg.Done()
+			r.Track(txn, nil)
+			r.Track(txn, nil)
+		}(txn)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				r.Check(ctx)
+			}
+		}()
+	}
+	wg.Wait()
+	r.Check(ctx)
+	assert.Len(t, r.Tracked(), len(txs))
+}
@@ -0,0 +1,212 @@
+package transactor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/tx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrTransactionDropped is returned when the node rejected or evicted the
+// transaction, or stopped knowing it after it was seen
+var ErrTransactionDropped = errors.New("transaction dropped")
+
+// WaitEventType is the kind of progress reported while waiting for a transaction
+type WaitEventType string
+
+const (
+	// EventPending is sent while the transaction waits in the mempool
+	EventPending WaitEventType = "pending"
+	// EventNotFound is sent while the node does not know the transaction
+	EventNotFound WaitEventType = "not_found"
+	// EventMined is sent when the transaction is mined but not yet confirmed enough
+	EventMined WaitEventType = "mined"
+	// EventConfirmed is sent once the transaction reached the required confirmations
+	EventConfirmed WaitEventType = "confirmed"
+	// EventReorged is sent when a mined transaction left its block because of a fork
+	EventReorged WaitEventType = "reorged"
+	// EventDropped is sent when the node rejected or evicted the transaction, or
+	// did not know it for too many polls after it was seen
+	EventDropped WaitEventType = "dropped"
+	// EventError is sent when polling the node failed, polling goes on afterwards
+	EventError WaitEventType = "error"
+)
+
+// WaitEvent reports the progress of WaitMined
+type WaitEvent struct {
+	Type   WaitEventType
+	TxID   string
+	Status *api.TransactionStatus // Last status received, nil on EventError
+	Err    error                  // Set on EventError
+}
+
+// WaitOption configures WaitMined
+type WaitOption func(*waitOptions)
+
+type waitOptions struct {
+	confirmations   int64
+	pollInterval    time.Duration
+	maxPollInterval time.Duration
+	backoffFactor   float64
+	notFoundLimit   int
+	onProgress      func(WaitEvent)
+}
+
+// WithConfirmations sets the number of confirmations to wait for, 1 by default
+// which returns as soon as the transaction is mined
+func WithConfirmations(n int64) WaitOption {
+	return func(o *waitOptions) {
+		o.confirmations = n
+	}
+}
+
+// WithPollInterval sets the time between two status requests, 1 second by default
+func WithPollInterval(d time.Duration) WaitOption {
+	return func(o *waitOptions) {
+		o.pollInterval = d
+	}
+}
+
+// WithBackoff multiplies the poll interval by factor, up to max, every time the
+// status of the transaction did not change. It goes back to the poll interval
+// on every change
+func WithBackoff(max time.Duration, factor float64) WaitOption {
+	return func(o *waitOptions) {
+		o.maxPollInterval = max
+		o.backoffFactor = factor
+	}
+}
+
+// WithNotFoundLimit sets the number of polls in a row the node may not know a
+// transaction it had pending or mined before it is considered dropped, 10 by
+// default. Nodes evict transactions from their mempool without always reporting
+// them as dropped. A limit of 0 or less waits until ctx is done
+func WithNotFoundLimit(n int) WaitOption {
+	return func(o *waitOptions) {
+		o.notFoundLimit = n
+	}
+}
+
+// WithProgress sets a callback receiving the progress events. It is called from
+// the goroutine running WaitMined
+func WithProgress(fn func(WaitEvent)) WaitOption {
+	return func(o *waitOptions) {
+		o.onProgress = fn
+	}
+}
+
+// WaitMined waits for the transaction to be mined with the required number of
+// confirmations, and returns it as stored by the node. If the transaction
+// leaves its block because of a fork, it goes back to waiting for it to be mined
+func (tr *Transactor) WaitMined(ctx context.Context, txn *tx.Transaction, opts ...WaitOption) (receipt *tx.Transaction, err error) {
+	o := &waitOptions{
+		confirmations: 1,
+		pollInterval:  time.Second,
+		backoffFactor: 1,
+		notFoundLimit: 10,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	ctx, span := tr.tracer().Start(ctx, "WaitMined", trace.WithAttributes(
+		attribute.String("arweave.tx", txn.Hash()),
+		attribute.Int64("arweave.confirmations", o.confirmations),
+	))
+	defer func() { endSpan(span, err) }()
+
+	if tr.Metrics != nil {
+		tr.Metrics.AddPendingConfirmations(1)
+		defer tr.Metrics.AddPendingConfirmations(-1)
+	}
+	notify := func(e WaitEvent) {
+		e.TxID = txn.Hash()
+		tr.logWaitEvent(e)
+		span.AddEvent(string(e.Type))
+		if o.onProgress != nil {
+			o.onProgress(e)
+		}
+	}
+
+	interval := o.pollInterval
+	var last *api.TransactionStatus
+	// the polls in a row not finding a transaction the node knew
+	seen, notFound := false, 0
+	for {
+		status, err := tr.Client.GetTransactionStatus(ctx, txn.Hash())
+		if err != nil {
+			notify(WaitEvent{Type: EventError, Err: err})
+		} else {
+			// a mined transaction which is not anymore, or in another block, was forked out
+			if last != nil && last.State == api.StatusMined &&
+				(status.State != api.StatusMined || status.BlockIndepHash != last.BlockIndepHash) {
+				notify(WaitEvent{Type: EventReorged, Status: status})
+			}
+			switch status.State {
+			case api.StatusDropped:
+				notify(WaitEvent{Type: EventDropped, Status: status})
+				return nil, ErrTransactionDropped
+			case api.StatusMined:
+				if status.Confirmed(o.confirmations) {
+					notify(WaitEvent{Type: EventConfirmed, Status: status})
+					return tr.Client.GetTransaction(ctx, txn.Hash())
+				}
+				notify(WaitEvent{Type: EventMined, Status: status})
+			case api.StatusPending:
+				notify(WaitEvent{Type: EventPending, Status: status})
+			default:
+				if seen {
+					notFound++
+				}
+				if o.notFoundLimit > 0 && notFound >= o.notFoundLimit {
+					notify(WaitEvent{Type: EventDropped, Status: status})
+					return nil, ErrTransactionDropped
+				}
+				notify(WaitEvent{Type: EventNotFound, Status: status})
+			}
+			if status.State == api.StatusPending || status.State == api.StatusMined {
+				seen, notFound = true, 0
+			}
+
+			if last != nil && *last == *status {
+				interval = nextInterval(interval, o)
+			} else {
+				interval = o.pollInterval
+			}
+			last = status
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func nextInterval(interval time.Duration, o *waitOptions) time.Duration {
+	if o.backoffFactor <= 1 || o.maxPollInterval <= 0 {
+		return interval
+	}
+	next := time.Duration(float64(interval) * o.backoffFactor)
+	if next > o.maxPollInterval {
+		return o.maxPollInterval
+	}
+	return next
+}
+
+func (tr *Transactor) logWaitEvent(e WaitEvent) {
+	switch e.Type {
+	case EventError:
+		tr.logger().Warn("polling transaction status failed", "tx", e.TxID, "error", e.Err)
+	case EventReorged, EventDropped:
+		tr.logger().Warn("transaction "+string(e.Type), "tx", e.TxID, "state", e.Status.State, "reason", e.Status.Reason)
+	case EventConfirmed:
+		tr.logger().Info("transaction confirmed", "tx", e.TxID, "block", e.Status.BlockIndepHash, "height", e.Status.BlockHeight, "confirmations", e.Status.Confirmations)
+	default:
+		tr.logger().Debug("waiting for transaction", "tx", e.TxID, "state", e.Status.State, "confirmations", e.Status.Confirmations)
+	}
+}
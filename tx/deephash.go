@@ -0,0 +1,45 @@
+package tx
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"strconv"
+)
+
+// DeepHash computes the SHA-384 based deep hash used by arweave to sign format 2
+// transactions and to hash blocks. The data must be a []byte, a string (hashed as
+// its bytes) or a list ([]interface{} or [][]byte) of those, lists can be nested
+func DeepHash(data interface{}) ([]byte, error) {
+	switch d := data.(type) {
+	case []byte:
+		tag := append([]byte("blob"), strconv.Itoa(len(d))...)
+		return sha384(sha384(tag), sha384(d)), nil
+	case string:
+		return DeepHash([]byte(d))
+	case [][]byte:
+		list := make([]interface{}, 0, len(d))
+		for _, item := range d {
+			list = append(list, item)
+		}
+		return DeepHash(list)
+	case []interface{}:
+		acc := sha384([]byte("list" + strconv.Itoa(len(d))))
+		for _, item := range d {
+			h, err := DeepHash(item)
+			if err != nil {
+				return nil, err
+			}
+			acc = sha384(acc, h)
+		}
+		return acc, nil
+	}
+	return nil, fmt.Errorf("cannot deep hash type %T", data)
+}
+
+func sha384(parts ...[]byte) []byte {
+	h := sha512.New384()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,248 @@
+package tx
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+
+	"github.com/Dev43/arweave-go/utils"
+)
+
+const (
+	// MaxChunkSize is the maximum size of a data chunk
+	MaxChunkSize = 256 * 1024
+	// MinChunkSize is the minimum size of a data chunk, the last two chunks are
+	// rebalanced so that the last one is not smaller than this
+	MinChunkSize = 32 * 1024
+	// noteSize is the size of the offsets hashed in the merkle tree
+	noteSize = 32
+)
+
+// MerkleLeaf is a leaf of an arweave merkle tree. Data is hashed together with the
+// end offset of the element it commits to
+type MerkleLeaf struct {
+	Data   []byte
+	Offset int64
+}
+
+type merkleNode struct {
+	id     []byte
+	offset int64
+}
+
+// MerkleRoot computes the root of the merkle tree built over the leaves. Nodes
+// without a sibling are carried over to the next level unchanged
+func MerkleRoot(leaves []MerkleLeaf) []byte {
+	if len(leaves) == 0 {
+		return nil
+	}
+	nodes := make([]merkleNode, 0, len(leaves))
+	for _, l := range leaves {
+		nodes = append(nodes, merkleNode{
+			id:     sha256Sum(sha256Sum(l.Data), sha256Sum(note(l.Offset))),
+			offset: l.Offset,
+		})
+	}
+	for len(nodes) > 1 {
+		next := make([]merkleNode, 0, (len(nodes)+1)/2)
+		for i := 0; i < len(nodes); i += 2 {
+			if i+1 == len(nodes) {
+				next = append(next, nodes[i])
+				continue
+			}
+			left, right := nodes[i], nodes[i+1]
+			next = append(next, merkleNode{
+				id:     sha256Sum(sha256Sum(left.id), sha256Sum(right.id), sha256Sum(note(left.offset))),
+				offset: right.offset,
+			})
+		}
+		nodes = next
+	}
+	return nodes[0].id
+}
+
+// ChunkData splits data into chunks the way clients do for format 2 transactions:
+// MaxChunkSize chunks, with the last two rebalanced so none is under MinChunkSize
+func ChunkData(data []byte) [][]byte {
+	chunks := [][]byte{}
+	rest := data
+	for len(rest) >= MaxChunkSize {
+		size := MaxChunkSize
+		next := len(rest) - MaxChunkSize
+		if next > 0 && next < MinChunkSize {
+			size = (len(rest) + 1) / 2
+		}
+		chunks = append(chunks, rest[:size])
+		rest = rest[size:]
+	}
+	return append(chunks, rest)
+}
+
+// GenerateDataRoot computes the data root of format 2 transaction data. Empty
+// data has an empty data root
+func GenerateDataRoot(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	return chunksRoot(ChunkData(data))
+}
+
+// generateFormat1DataRoot computes the data root nodes derive for format 1
+// transactions, whose data is split in fixed size chunks
+func generateFormat1DataRoot(data []byte) []byte {
+	chunks := [][]byte{}
+	rest := data
+	for len(rest) >= MaxChunkSize {
+		chunks = append(chunks, rest[:MaxChunkSize])
+		rest = rest[MaxChunkSize:]
+	}
+	return chunksRoot(append(chunks, rest))
+}
+
+func chunksRoot(chunks [][]byte) []byte {
+	leaves := make([]MerkleLeaf, 0, len(chunks))
+	offset := int64(0)
+	for _, c := range chunks {
+		offset += int64(len(c))
+		leaves = append(leaves, MerkleLeaf{Data: sha256Sum(c), Offset: offset})
+	}
+	return MerkleRoot(leaves)
+}
+
+// chunkPaths returns the merkle proof of every chunk, the data path sent along
+// with the chunk: the left id, right id and left offset of each branch from the
+// root down to the leaf, then the leaf's data hash and offset
+func chunkPaths(chunks [][]byte) [][]byte {
+	type pathNode struct {
+		merkleNode
+		leaves []int // indexes of the chunks under the node
+	}
+	paths := make([][]byte, len(chunks))
+	nodes := make([]pathNode, 0, len(chunks))
+	offset := int64(0)
+	for i, c := range chunks {
+		offset += int64(len(c))
+		hash := sha256Sum(c)
+		paths[i] = append(hash, note(offset)...)
+		nodes = append(nodes, pathNode{
+			merkleNode: merkleNode{id: sha256Sum(sha256Sum(hash), sha256Sum(note(offset))), offset: offset},
+			leaves:     []int{i},
+		})
+	}
+	for len(nodes) > 1 {
+		next := make([]pathNode, 0, (len(nodes)+1)/2)
+		for i := 0; i < len(nodes); i += 2 {
+			if i+1 == len(nodes) {
+				next = append(next, nodes[i])
+				continue
+			}
+			left, right := nodes[i], nodes[i+1]
+			branch := make([]byte, 0, 3*noteSize)
+			branch = append(branch, left.id...)
+			branch = append(branch, right.id...)
+			branch = append(branch, note(left.offset)...)
+			leaves := append(append([]int{}, left.leaves...), right.leaves...)
+			for _, l := range leaves {
+				paths[l] = append(append([]byte{}, branch...), paths[l]...)
+			}
+			next = append(next, pathNode{
+				merkleNode: merkleNode{
+					id:     sha256Sum(sha256Sum(left.id), sha256Sum(right.id), sha256Sum(note(left.offset))),
+					offset: right.offset,
+				},
+				leaves: leaves,
+			})
+		}
+		nodes = next
+	}
+	return paths
+}
+
+// Chunk is a piece of the data of a format 2 transaction, uploaded to the nodes
+// with the proof that it belongs to the data root of the transaction
+type Chunk struct {
+	DataRoot string // Base64url encoded
+	DataSize int64
+	DataPath []byte
+	Offset   int64 // Offset of the last byte of the chunk in the data
+	Data     []byte
+}
+
+type chunkJSON struct {
+	DataRoot string `json:"data_root"`
+	DataSize string `json:"data_size"`
+	DataPath string `json:"data_path"`
+	Offset   string `json:"offset"`
+	Chunk    string `json:"chunk"`
+}
+
+// MarshalJSON marshals the chunk the way the /chunk endpoint expects it
+func (c *Chunk) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&chunkJSON{
+		DataRoot: c.DataRoot,
+		DataSize: strconv.FormatInt(c.DataSize, 10),
+		DataPath: utils.EncodeToBase64(c.DataPath),
+		Offset:   strconv.FormatInt(c.Offset, 10),
+		Chunk:    utils.EncodeToBase64(c.Data),
+	})
+}
+
+// Chunks splits the data of a format 2 transaction into the chunks uploaded to
+// the nodes. Empty chunks, which ChunkData leaves when the data is a multiple of
+// MaxChunkSize, are not returned
+func (t *Transaction) Chunks() ([]*Chunk, error) {
+	if t.formatVersion != 2 {
+		return nil, errors.New("only the data of format 2 transactions is uploaded in chunks")
+	}
+	if int64(len(t.data)) != t.dataSize {
+		return nil, errors.New("the transaction does not hold its data")
+	}
+	if len(t.data) == 0 {
+		return []*Chunk{}, nil
+	}
+	data := ChunkData(t.data)
+	paths := chunkPaths(data)
+	chunks := make([]*Chunk, 0, len(data))
+	offset := int64(0)
+	for i, d := range data {
+		offset += int64(len(d))
+		if len(d) == 0 {
+			continue
+		}
+		chunks = append(chunks, &Chunk{
+			DataRoot: t.DataRoot(),
+			DataSize: t.dataSize,
+			DataPath: paths[i],
+			Offset:   offset - 1,
+			Data:     d,
+		})
+	}
+	return chunks, nil
+}
+
+// ComputeDataRoot returns the data root of the transaction: the one it commits to
+// for format 2 transactions, or the one derived from its inline data for format 1
+func (t *Transaction) ComputeDataRoot() []byte {
+	if t.formatVersion == 2 {
+		return t.dataRoot
+	}
+	return generateFormat1DataRoot(t.data)
+}
+
+// note encodes an offset as a 32 bytes big endian integer
+func note(offset int64) []byte {
+	b := big.NewInt(offset).Bytes()
+	n := make([]byte, noteSize)
+	copy(n[noteSize-len(b):], b)
+	return n
+}
+
+func sha256Sum(parts ...[]byte) []byte {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+	}
+	return h.Sum(nil)
+}
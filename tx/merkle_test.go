@@ -0,0 +1,108 @@
+package tx
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkData(t *testing.T) {
+	cases := []struct {
+		size   int
+		chunks []int
+	}{
+		{0, []int{0}},
+		{100, []int{100}},
+		{MaxChunkSize, []int{MaxChunkSize, 0}},
+		{MaxChunkSize + MinChunkSize, []int{MaxChunkSize, MinChunkSize}},
+		// the remainder would be too small, so the last two chunks are rebalanced
+		{MaxChunkSize + 1000, []int{(MaxChunkSize + 1001) / 2, (MaxChunkSize + 1000) / 2}},
+		{2*MaxChunkSize + 1, []int{MaxChunkSize, (MaxChunkSize + 2) / 2, (MaxChunkSize + 1) / 2}},
+	}
+
+	for _, c := range cases {
+		chunks := ChunkData(make([]byte, c.size))
+		sizes := []int{}
+		for _, chunk := range chunks {
+			sizes = append(sizes, len(chunk))
+		}
+		assert.Equal(t, c.chunks, sizes, "wrong chunks for %d bytes", c.size)
+	}
+}
+
+func TestMerkleRoot(t *testing.T) {
+	leaves := []MerkleLeaf{
+		{Data: []byte("a"), Offset: 1},
+		{Data: []byte("b"), Offset: 2},
+		{Data: []byte("c"), Offset: 3},
+	}
+	left := sha256Sum(sha256Sum([]byte("a")), sha256Sum(note(1)))
+	right := sha256Sum(sha256Sum([]byte("b")), sha256Sum(note(2)))
+	last := sha256Sum(sha256Sum([]byte("c")), sha256Sum(note(3)))
+	branch := sha256Sum(sha256Sum(left), sha256Sum(right), sha256Sum(note(1)))
+	root := sha256Sum(sha256Sum(branch), sha256Sum(last), sha256Sum(note(2)))
+
+	assert.Equal(t, root, MerkleRoot(leaves))
+	assert.Equal(t, left, MerkleRoot(leaves[:1]), "a single leaf is its own root")
+	assert.Nil(t, MerkleRoot(nil))
+}
+
+func TestFormat1DataRoot(t *testing.T) {
+	data := make([]byte, MaxChunkSize+1000)
+	// format 1 data is split in fixed size chunks, unlike format 2 data
+	assert.NotEqual(t, GenerateDataRoot(data), generateFormat1DataRoot(data))
+	assert.Equal(t, GenerateDataRoot(data[:1000]), generateFormat1DataRoot(data[:1000]))
+}
+
+// walkPath follows a data path from the root the way nodes validate it, returning
+// the data hash and the end offset of the leaf reached for the offset dest
+func walkPath(id []byte, dest int64, path []byte) ([]byte, int64, bool) {
+	if len(path) == 2*noteSize {
+		hash, end := path[:noteSize], path[noteSize:]
+		if string(sha256Sum(sha256Sum(hash), sha256Sum(end))) != string(id) {
+			return nil, 0, false
+		}
+		return hash, new(big.Int).SetBytes(end).Int64(), true
+	}
+	if len(path) < 3*noteSize {
+		return nil, 0, false
+	}
+	left, right, offset := path[:noteSize], path[noteSize:2*noteSize], path[2*noteSize:3*noteSize]
+	if string(sha256Sum(sha256Sum(left), sha256Sum(right), sha256Sum(offset))) != string(id) {
+		return nil, 0, false
+	}
+	if dest < new(big.Int).SetBytes(offset).Int64() {
+		return walkPath(left, dest, path[3*noteSize:])
+	}
+	return walkPath(right, dest, path[3*noteSize:])
+}
+
+func TestChunks(t *testing.T) {
+	for _, size := range []int{1, 1000, MaxChunkSize, 2*MaxChunkSize + 1, 5*MaxChunkSize + MinChunkSize} {
+		data := make([]byte, size)
+		rand.Read(data)
+		txn := NewTransaction("", big.NewInt(1), arweave.NewWinston(0), "", data, arweave.NewWinston(1))
+		assert.NoError(t, txn.SetFormat(2))
+
+		chunks, err := txn.Chunks()
+		assert.NoError(t, err)
+		assembled := []byte{}
+		for _, c := range chunks {
+			assert.Equal(t, txn.DataRoot(), c.DataRoot)
+			assert.Equal(t, int64(size), c.DataSize)
+			hash, end, ok := walkPath(txn.dataRoot, c.Offset, c.DataPath)
+			if assert.True(t, ok, "invalid data path for %d bytes at %d", size, c.Offset) {
+				assert.Equal(t, sha256Sum(c.Data), hash)
+				assert.Equal(t, c.Offset+1, end)
+			}
+			assembled = append(assembled, c.Data...)
+		}
+		assert.Equal(t, data, assembled)
+	}
+
+	_, err := NewTransaction("", big.NewInt(1), arweave.NewWinston(0), "", []byte("a"), arweave.NewWinston(1)).Chunks()
+	assert.Error(t, err, "format 1 data is not chunked")
+}
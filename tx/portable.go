@@ -0,0 +1,127 @@
+package tx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/Dev43/arweave-go"
+)
+
+// portableVersion is the version of the file format of exported transactions
+const portableVersion = 1
+
+// portableTransaction is the envelope of an exported transaction. The transaction
+// is in the same JSON format as the one sent to the nodes
+type portableTransaction struct {
+	Version     int          `json:"version"`
+	Signed      bool         `json:"signed"`
+	Transaction *Transaction `json:"transaction"`
+}
+
+// Export writes the transaction to w so it can be moved to another machine, for
+// example to an offline machine holding the wallet, which signs it with SignFile.
+// The anchor and the reward must already be set since they require a node
+func (t *Transaction) Export(w io.Writer) error {
+	if t.owner == nil {
+		return errors.New("transaction missing owner")
+	}
+	if t.lastTx == "" {
+		return errors.New("transaction missing anchor")
+	}
+	if t.reward.Sign() <= 0 {
+		return errors.New("transaction missing reward")
+	}
+	b, err := json.MarshalIndent(&portableTransaction{
+		Version:     portableVersion,
+		Signed:      len(t.signature) > 0,
+		Transaction: t,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// ExportFile writes the transaction to the file at path, see Export
+func (t *Transaction) ExportFile(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	err = t.Export(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Import reads a transaction written by Export. The signature of signed
+// transactions is verified, they can be sent as they are
+func Import(r io.Reader) (*Transaction, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := portableTransaction{}
+	err = json.Unmarshal(b, &p)
+	if err != nil {
+		return nil, err
+	}
+	if p.Version != portableVersion {
+		return nil, fmt.Errorf("unsupported transaction file version %d", p.Version)
+	}
+	if p.Transaction == nil {
+		return nil, errors.New("transaction file has no transaction")
+	}
+	if p.Signed != (len(p.Transaction.signature) > 0) {
+		return nil, errors.New("transaction file signature flag does not match the transaction")
+	}
+	if p.Signed {
+		err = p.Transaction.VerifySignature()
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction signature: %v", err)
+		}
+	}
+	return p.Transaction, nil
+}
+
+// ImportFile reads a transaction from the file at path, see Import
+func ImportFile(path string) (*Transaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Import(f)
+}
+
+// SignFile signs the unsigned transaction exported to src with the wallet and
+// exports the signed transaction to dst. It needs no network access, the wallet
+// must be the owner of the transaction
+func SignFile(src string, dst string, w arweave.WalletSigner) (*Transaction, error) {
+	t, err := ImportFile(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(t.signature) > 0 {
+		return nil, errors.New("transaction is already signed")
+	}
+	if t.OwnerAddress() != w.Address() {
+		return nil, fmt.Errorf("transaction owned by %s cannot be signed by %s", t.OwnerAddress(), w.Address())
+	}
+	signed, err := t.Sign(w)
+	if err != nil {
+		return nil, err
+	}
+	err = signed.ExportFile(dst)
+	if err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
@@ -0,0 +1,92 @@
+package tx
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfflineSigning(t *testing.T) {
+	w := wallet.NewWallet()
+	err := w.LoadKeyFromFile(filepath.Join("..", "wallet", "testdata", "arweave-test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "portable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	unsignedPath := filepath.Join(dir, "unsigned.json")
+	signedPath := filepath.Join(dir, "signed.json")
+
+	// the online machine only knows the public key
+	online := wallet.NewWallet()
+	err = online.LoadPublicKey(w.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn := NewTransaction("anchor", online.PubKeyModulus(), arweave.Amount{}, "", []byte("hello"), arweave.NewWinston(1000))
+	assert.NoError(t, txn.AddTag("App-Name", "test"))
+	assert.NoError(t, txn.SetFormat(2))
+	assert.NoError(t, txn.ExportFile(unsignedPath))
+
+	_, err = SignFile(unsignedPath, signedPath, online)
+	assert.Equal(t, wallet.ErrNoPrivateKey, err)
+
+	signed, err := SignFile(unsignedPath, signedPath, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := ImportFile(signedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, signed.Hash(), imported.Hash())
+	assert.Equal(t, signed.Signature(), imported.Signature())
+	assert.Equal(t, 2, imported.Format())
+	assert.Equal(t, txn.DataRoot(), imported.DataRoot())
+	assert.Equal(t, []Tag{{Name: "App-Name", Value: "test"}}, mustTags(t, imported))
+
+	// signed transactions cannot be signed again
+	_, err = SignFile(signedPath, unsignedPath, w)
+	assert.Error(t, err)
+}
+
+func TestImportTamperedTransaction(t *testing.T) {
+	w := wallet.NewWallet()
+	err := w.LoadKeyFromFile(filepath.Join("..", "wallet", "testdata", "arweave-test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn := NewTransaction("anchor", w.PubKeyModulus(), arweave.Amount{}, "", []byte("hello"), arweave.NewWinston(1000))
+	signed, err := txn.Sign(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed.reward = arweave.NewWinston(1)
+
+	dir, err := ioutil.TempDir("", "portable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tampered.json")
+	assert.NoError(t, signed.ExportFile(path))
+	_, err = ImportFile(path)
+	assert.Error(t, err)
+}
+
+func mustTags(t *testing.T, txn *Transaction) []Tag {
+	tags, err := txn.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tags
+}
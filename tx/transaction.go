@@ -1,24 +1,32 @@
 package tx
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
+	"strconv"
 
 	"github.com/Dev43/arweave-go"
 	"github.com/Dev43/arweave-go/utils"
 )
 
 // NewTransaction creates a brand new transaction struct
-func NewTransaction(lastTx string, owner *big.Int, quantity string, target string, data []byte, reward string) *Transaction {
+func NewTransaction(lastTx string, owner *big.Int, quantity arweave.Amount, target string, data []byte, reward arweave.Amount) *Transaction {
 	return &Transaction{
-		lastTx:   lastTx,
-		owner:    owner,
-		quantity: quantity,
-		target:   target,
-		data:     data,
-		reward:   reward,
-		tags:     make([]Tag, 0),
+		formatVersion: 1,
+		lastTx:        lastTx,
+		owner:         owner,
+		quantity:      quantity,
+		target:        target,
+		data:          data,
+		dataSize:      int64(len(data)),
+		reward:        reward,
+		tags:          make([]Tag, 0),
 	}
 }
 
@@ -32,6 +40,21 @@ func (t *Transaction) RawData() []byte {
 	return t.data
 }
 
+// Format returns the format of the transaction, 1 or 2
+func (t *Transaction) Format() int {
+	return t.formatVersion
+}
+
+// DataSize returns the size in bytes of the transaction data
+func (t *Transaction) DataSize() int64 {
+	return t.dataSize
+}
+
+// DataRoot returns the base64 RawURLEncoding of the data root, empty for format 1 transactions
+func (t *Transaction) DataRoot() string {
+	return utils.EncodeToBase64(t.dataRoot)
+}
+
 // LastTx returns the last transaction of the account
 func (t *Transaction) LastTx() string {
 	return t.lastTx
@@ -42,13 +65,20 @@ func (t *Transaction) Owner() string {
 	return utils.EncodeToBase64(t.owner.Bytes())
 }
 
+// OwnerAddress returns the address of the owner, the base64 RawURLEncoding of the
+// SHA256 of its public key modulus
+func (t *Transaction) OwnerAddress() string {
+	h := sha256.Sum256(t.owner.Bytes())
+	return utils.EncodeToBase64(h[:])
+}
+
 // Quantity returns the quantity of the transaction
-func (t *Transaction) Quantity() string {
+func (t *Transaction) Quantity() arweave.Amount {
 	return t.quantity
 }
 
 // Reward returns the reward of the transaction
-func (t *Transaction) Reward() string {
+func (t *Transaction) Reward() arweave.Amount {
 	return t.reward
 }
 
@@ -100,6 +130,39 @@ func (t *Transaction) AddTag(name string, value string) error {
 	return nil
 }
 
+// SetData sets the raw data of the transaction, for instance when a node did
+// not inline it. The data root of format 2 transactions is computed again
+func (t *Transaction) SetData(data []byte) {
+	t.data = data
+	t.dataSize = int64(len(data))
+	if t.formatVersion == 2 {
+		t.dataRoot = GenerateDataRoot(data)
+	}
+}
+
+// Header returns a copy of the transaction without its data, to send a format 2
+// transaction whose data is then uploaded in chunks
+func (t *Transaction) Header() *Transaction {
+	h := *t
+	h.data = nil
+	return &h
+}
+
+// SetFormat sets the format of an unsigned transaction, 1 or 2. Format 2
+// transactions commit to the root of their data chunks instead of the data itself
+func (t *Transaction) SetFormat(format int) error {
+	switch format {
+	case 1:
+		t.dataRoot = nil
+	case 2:
+		t.dataRoot = GenerateDataRoot(t.data)
+	default:
+		return fmt.Errorf("unsupported transaction format %d", format)
+	}
+	t.formatVersion = format
+	return nil
+}
+
 func (t *Transaction) SetID(id []byte) {
 	t.id = id
 }
@@ -148,6 +211,32 @@ func (t *Transaction) Sign(w arweave.WalletSigner) (*Transaction, error) {
 	return &tx, nil
 }
 
+// VerifySignature checks that the transaction is signed by its owner and that its
+// ID is the hash of the signature
+func (t *Transaction) VerifySignature() error {
+	if len(t.signature) == 0 {
+		return errors.New("transaction missing signature")
+	}
+	if t.owner == nil {
+		return errors.New("transaction missing owner")
+	}
+	id := sha256.Sum256(t.signature)
+	if !bytes.Equal(id[:], t.id) {
+		return errors.New("transaction id does not match its signature")
+	}
+	payload, err := t.FormatMsgBytes()
+	if err != nil {
+		return err
+	}
+	msg := sha256.Sum256(payload)
+	// Arweave keys always use 65537 as their public exponent
+	pub := &rsa.PublicKey{N: t.owner, E: 65537}
+	return rsa.VerifyPSS(pub, crypto.SHA256, msg[:], t.signature, &rsa.PSSOptions{
+		SaltLength: rsa.PSSSaltLengthAuto,
+		Hash:       crypto.SHA256,
+	})
+}
+
 // MarshalJSON marshals as JSON
 func (t *Transaction) MarshalJSON() ([]byte, error) {
 	return json.Marshal(t.format())
@@ -166,6 +255,11 @@ func (t *Transaction) UnmarshalJSON(input []byte) error {
 	}
 	t.id = id
 
+	t.formatVersion = txn.Format
+	if t.formatVersion == 0 {
+		t.formatVersion = 1
+	}
+
 	t.lastTx = txn.LastTx
 
 	// gives me byte representation of the big num
@@ -178,14 +272,32 @@ func (t *Transaction) UnmarshalJSON(input []byte) error {
 
 	t.tags = txn.Tags
 	t.target = txn.Target
-	t.quantity = txn.Quantity
+	t.quantity, err = arweave.ParseWinston(txn.Quantity)
+	if err != nil {
+		return err
+	}
 
 	data, err := utils.DecodeString(txn.Data)
 	if err != nil {
 		return err
 	}
 	t.data = data
-	t.reward = txn.Reward
+	t.dataSize = int64(len(data))
+	if txn.DataSize != "" {
+		t.dataSize, err = strconv.ParseInt(txn.DataSize, 10, 64)
+		if err != nil {
+			return err
+		}
+	}
+	dataRoot, err := utils.DecodeString(txn.DataRoot)
+	if err != nil {
+		return err
+	}
+	t.dataRoot = dataRoot
+	t.reward, err = arweave.ParseWinston(txn.Reward)
+	if err != nil {
+		return err
+	}
 
 	sig, err := utils.DecodeString(txn.Signature)
 	if err != nil {
@@ -200,7 +312,11 @@ func (t *Transaction) UnmarshalJSON(input []byte) error {
 // need to be an array of bytes originating from the necessary data (not base64url encoded).
 // The signing message is the SHA256 of the concatenation of the byte arrays
 // of the owner public key, target address, data, quantity, reward and last transaction
+// for format 1 transactions, and of their deep hash for format 2 transactions
 func (t *Transaction) FormatMsgBytes() ([]byte, error) {
+	if t.formatVersion == 2 {
+		return t.formatMsgBytesV2()
+	}
 	var msg []byte
 	lastTx, err := utils.DecodeString(t.LastTx())
 	if err != nil {
@@ -219,14 +335,47 @@ func (t *Transaction) FormatMsgBytes() ([]byte, error) {
 	msg = append(msg, t.owner.Bytes()...)
 	msg = append(msg, target...)
 	msg = append(msg, t.data...)
-	msg = append(msg, t.quantity...)
-	msg = append(msg, t.reward...)
+	msg = append(msg, t.quantity.String()...)
+	msg = append(msg, t.reward.String()...)
 	msg = append(msg, lastTx...)
 	msg = append(msg, tags...)
 
 	return msg, nil
 }
 
+// formatMsgBytesV2 deep hashes the fields of a format 2 transaction, which commits
+// to its data through the data root and data size
+func (t *Transaction) formatMsgBytesV2() ([]byte, error) {
+	lastTx, err := utils.DecodeString(t.LastTx())
+	if err != nil {
+		return nil, err
+	}
+	target, err := utils.DecodeString(t.Target())
+	if err != nil {
+		return nil, err
+	}
+	unencodedTags, err := t.Tags()
+	if err != nil {
+		return nil, err
+	}
+	tags := []interface{}{}
+	for _, tag := range unencodedTags {
+		tags = append(tags, [][]byte{[]byte(tag.Name), []byte(tag.Value)})
+	}
+
+	return DeepHash([]interface{}{
+		strconv.Itoa(t.formatVersion),
+		t.owner.Bytes(),
+		target,
+		t.quantity.String(),
+		t.reward.String(),
+		lastTx,
+		tags,
+		strconv.FormatInt(t.dataSize, 10),
+		t.dataRoot,
+	})
+}
+
 // We need to encode the tag data properly for the signature. This means having the unencoded
 // value of the Name field concatenated with the unencoded value of the Value field
 func (t *Transaction) encodeTagData() (string, error) {
@@ -245,14 +394,17 @@ func (t *Transaction) encodeTagData() (string, error) {
 // Format formats the transactions to a JSONTransaction that can be sent out to an arweave node
 func (t *Transaction) format() *transactionJSON {
 	return &transactionJSON{
+		Format:    t.formatVersion,
 		ID:        utils.EncodeToBase64(t.id),
 		LastTx:    t.lastTx,
 		Owner:     utils.EncodeToBase64(t.owner.Bytes()),
 		Tags:      t.tags,
 		Target:    t.target,
-		Quantity:  t.quantity,
+		Quantity:  t.quantity.String(),
 		Data:      utils.EncodeToBase64(t.data),
-		Reward:    t.reward,
+		DataSize:  strconv.FormatInt(t.dataSize, 10),
+		DataRoot:  utils.EncodeToBase64(t.dataRoot),
+		Reward:    t.reward.String(),
 		Signature: utils.EncodeToBase64(t.signature),
 	}
 }
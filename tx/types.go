@@ -1,22 +1,31 @@
 package tx
 
-import "math/big"
+import (
+	"math/big"
+
+	"github.com/Dev43/arweave-go"
+)
 
 // Transaction struct
 type Transaction struct {
-	id        []byte   // A SHA2-256 hash of the signature
-	lastTx    string   // The ID of the last transaction made from the account. If no previous transactions have been made from the address this field is set to an empty string.
-	owner     *big.Int // The modulus of the RSA key pair corresponding to the wallet making the transaction
-	target    string   // If making a financial transaction this field contains the wallet address of the recipient base64url encoded. If the transaction is not a financial this field is set to an empty string.
-	quantity  string   // If making a financial transaction this field contains the amount in Winston to be sent to the receiving wallet. If the transaction is not financial this field is set to the string "0". 1 AR = 1000000000000 (1e+12) Winston
-	data      []byte   // If making an archiving transaction this field contains the data to be archived base64url encoded. If the transaction is not archival this field is set to an empty string.
-	reward    string   // This field contains the mining reward for the transaction in Winston.
-	tags      []Tag    // Transaction tags
-	signature []byte   // Signature using the RSA-PSS signature scheme using SHA256 as the MGF1 masking algorithm
+	formatVersion int            // The transaction format, 1 for transactions carrying their data inline, 2 for transactions committing to a data root
+	id            []byte         // A SHA2-256 hash of the signature
+	lastTx        string         // The ID of the last transaction made from the account. If no previous transactions have been made from the address this field is set to an empty string.
+	owner         *big.Int       // The modulus of the RSA key pair corresponding to the wallet making the transaction
+	target        string         // If making a financial transaction this field contains the wallet address of the recipient base64url encoded. If the transaction is not a financial this field is set to an empty string.
+	quantity      arweave.Amount // If making a financial transaction this field contains the amount in Winston to be sent to the receiving wallet. If the transaction is not financial this field is set to the string "0". 1 AR = 1000000000000 (1e+12) Winston
+	data          []byte         // If making an archiving transaction this field contains the data to be archived base64url encoded. If the transaction is not archival this field is set to an empty string.
+	dataSize      int64          // The size in bytes of the transaction data
+	dataRoot      []byte         // The merkle root of the transaction data chunks, only set on format 2 transactions
+	reward        arweave.Amount // This field contains the mining reward for the transaction in Winston.
+	tags          []Tag          // Transaction tags
+	signature     []byte         // Signature using the RSA-PSS signature scheme using SHA256 as the MGF1 masking algorithm
 }
 
 // Transaction encoded transaction to send to the arweave client
 type transactionJSON struct {
+	// Format is the transaction format, 1 or 2. Transactions without one are format 1
+	Format int `json:"format,omitempty"`
 	// Id A SHA2-256 hash of the signature, based 64 URL encoded.
 	ID string `json:"id"`
 	// LastTx represents the ID of the last transaction made from the same address base64url encoded. If no previous transactions have been made from the address this field is set to an empty string.
@@ -29,6 +38,10 @@ type transactionJSON struct {
 	Quantity string `json:"quantity"`
 	// Data If making an archiving transaction this field contains the data to be archived base64url encoded. If the transaction is not archival this field is set to an empty string.
 	Data string `json:"data"`
+	// DataSize is the size in bytes of the transaction data, as a string
+	DataSize string `json:"data_size,omitempty"`
+	// DataRoot is the merkle root of the transaction data chunks base64url encoded. Empty on format 1 transactions
+	DataRoot string `json:"data_root,omitempty"`
 	// Reward This field contains the mining reward for the transaction in Winston.
 	Reward string `json:"reward"`
 	//  Signature using the RSA-PSS signature scheme using SHA256 as the MGF1 masking algorithm
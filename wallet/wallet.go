@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -13,6 +14,9 @@ import (
 	"github.com/mendsley/gojwk"
 )
 
+// ErrNoPrivateKey is returned when signing with a wallet loaded from its public key only
+var ErrNoPrivateKey = errors.New("wallet has no private key")
+
 var opts = &rsa.PSSOptions{
 	SaltLength: rsa.PSSSaltLengthAuto,
 	Hash:       crypto.SHA256,
@@ -64,8 +68,17 @@ func (w *Wallet) PubKeyModulus() *big.Int {
 	return w.pubKey.N
 }
 
+// PublicKey returns the base64url encoded modulus of the RSA public key, which is
+// the owner field of the wallet's transactions
+func (w *Wallet) PublicKey() string {
+	return w.publicKey
+}
+
 // Sign signs a message using the RSA-PSS scheme with an MGF SHA256 masking function
 func (w *Wallet) Sign(msg []byte) ([]byte, error) {
+	if w.key == nil || w.key.D == "" {
+		return nil, ErrNoPrivateKey
+	}
 	priv, err := w.key.DecodePrivateKey()
 	if err != nil {
 		return nil, err
@@ -138,3 +151,37 @@ func (w *Wallet) LoadKey(rsaKeyBytes []byte) error {
 
 	return nil
 }
+
+// LoadPublicKey loads the public key of an Arweave wallet, given as the base64url
+// encoded modulus of its RSA key (the owner field of its transactions). The wallet
+// can then create transactions on a machine without the private key, which are
+// signed elsewhere, but it cannot sign them itself
+func (w *Wallet) LoadPublicKey(owner string) error {
+	n, err := utils.DecodeString(owner)
+	if err != nil {
+		return err
+	}
+	if len(n) == 0 {
+		return errors.New("empty public key")
+	}
+	// Arweave keys always use 65537 as their public exponent
+	key := &gojwk.Key{
+		Kty: "RSA",
+		N:   owner,
+		E:   utils.EncodeToBase64(big.NewInt(65537).Bytes()),
+	}
+	publicKey, err := key.DecodePublicKey()
+	if err != nil {
+		return err
+	}
+	pubKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("could not typecast key to %T", rsa.PublicKey{})
+	}
+	w.pubKey = pubKey
+	h := sha256.Sum256(pubKey.N.Bytes())
+	w.address = utils.EncodeToBase64(h[:])
+	w.publicKey = utils.EncodeToBase64(pubKey.N.Bytes())
+	w.key = key
+	return nil
+}
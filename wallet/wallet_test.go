@@ -73,4 +73,29 @@ func TestGeneration(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-}
\ No newline at end of file
+}
+
+func TestLoadPublicKey(t *testing.T) {
+	full := NewWallet()
+	err := full.LoadKeyFromFile(filepath.Join("testdata", keyfileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWallet()
+	err = w.LoadPublicKey(full.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ensureCorrectCryptoValues(t, w)
+
+	msg := sha256.Sum256([]byte("hello"))
+	_, err = w.Sign(msg[:])
+	assert.Equal(t, ErrNoPrivateKey, err)
+
+	sig, err := full.Sign(msg[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, w.Verify(msg[:], sig))
+}
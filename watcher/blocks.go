@@ -0,0 +1,160 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Dev43/arweave-go/api"
+)
+
+// maxReorgDepth is the number of blocks kept by the BlockFollower to find where
+// a fork started
+const maxReorgDepth = 50
+
+// BlockCaller is the part of the api client used by the BlockFollower
+type BlockCaller interface {
+	GetInfo(ctx context.Context) (*api.NetworkInfo, error)
+	GetBlockByHeight(ctx context.Context, height int64) (*api.Block, error)
+}
+
+// BlockEventType is the kind of change of the chain
+type BlockEventType string
+
+const (
+	// BlockNew is sent for every block, in order of height
+	BlockNew BlockEventType = "new"
+	// BlockReorg is sent when blocks already sent were orphaned by a fork. The
+	// blocks of the fork are sent afterwards as new blocks
+	BlockReorg BlockEventType = "reorg"
+	// BlockError is sent when polling the node failed, polling goes on afterwards
+	BlockError BlockEventType = "error"
+)
+
+// BlockEvent reports a change of the chain
+type BlockEvent struct {
+	Type     BlockEventType
+	Block    *api.Block   // Set on BlockNew
+	Orphaned []*api.Block // Set on BlockReorg, in order of height
+	Err      error        // Set on BlockError
+}
+
+// BlockFollower polls a node for new blocks and reports them in order, fetching
+// the ones mined between two polls and detecting the forks
+type BlockFollower struct {
+	client   BlockCaller
+	next     int64 // height of the next block to send, -1 to start from the current one
+	interval time.Duration
+	history  []*api.Block // last blocks sent, to detect forks
+}
+
+// NewBlockFollower creates a follower starting from the block at height from, or
+// from the current block if from is negative, polling the node every interval,
+// 10 seconds if 0
+func NewBlockFollower(client BlockCaller, from int64, interval time.Duration) *BlockFollower {
+	if from < 0 {
+		from = -1
+	}
+	if interval == 0 {
+		interval = 10 * time.Second
+	}
+	return &BlockFollower{client: client, next: from, interval: interval}
+}
+
+// Next returns the height of the next block to be sent, which can be given to
+// NewBlockFollower to resume following the chain
+func (f *BlockFollower) Next() int64 {
+	return f.next
+}
+
+// Follow polls the node until ctx is done, sending the events to the returned
+// channel, which is closed once ctx is done
+func (f *BlockFollower) Follow(ctx context.Context) <-chan BlockEvent {
+	events := make(chan BlockEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(f.interval)
+		defer ticker.Stop()
+		for {
+			polled, err := f.Poll(ctx)
+			if err != nil {
+				polled = append(polled, BlockEvent{Type: BlockError, Err: err})
+			}
+			for _, e := range polled {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return events
+}
+
+// Poll returns the events since the previous poll. The events before an error
+// are returned with it, polling again resumes after them
+func (f *BlockFollower) Poll(ctx context.Context) ([]BlockEvent, error) {
+	info, err := f.client.GetInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	height := int64(info.Height)
+	if f.next < 0 {
+		f.next = height
+	}
+
+	events := []BlockEvent{}
+	for f.next <= height {
+		b, err := f.client.GetBlockByHeight(ctx, f.next)
+		if err != nil {
+			return events, err
+		}
+		if len(f.history) > 0 && b.PreviousBlock != f.history[len(f.history)-1].IndepHash {
+			orphaned, err := f.unwind(ctx)
+			if err != nil {
+				return events, err
+			}
+			if len(orphaned) == 0 {
+				// the node serves a block whose previous block is not the one at
+				// the height below, polling again gives it a chance to settle
+				return events, fmt.Errorf("block %d does not follow block %d of the node", b.Height, b.Height-1)
+			}
+			events = append(events, BlockEvent{Type: BlockReorg, Orphaned: orphaned})
+			continue
+		}
+		events = append(events, BlockEvent{Type: BlockNew, Block: b})
+		f.history = append(f.history, b)
+		if len(f.history) > maxReorgDepth {
+			f.history = f.history[1:]
+		}
+		f.next++
+	}
+	return events, nil
+}
+
+// unwind removes the blocks which are not on the chain of the node anymore from
+// the history, and returns them in order of height. If the fork is deeper than
+// the history, the whole history is orphaned
+func (f *BlockFollower) unwind(ctx context.Context) ([]*api.Block, error) {
+	orphaned := []*api.Block{}
+	for len(f.history) > 0 {
+		last := f.history[len(f.history)-1]
+		canonical, err := f.client.GetBlockByHeight(ctx, last.Height)
+		if err != nil {
+			return nil, err
+		}
+		if canonical.IndepHash == last.IndepHash {
+			break
+		}
+		orphaned = append([]*api.Block{last}, orphaned...)
+		f.history = f.history[:len(f.history)-1]
+		f.next = last.Height
+	}
+	return orphaned, nil
+}
@@ -0,0 +1,205 @@
+// Package watcher follows the activity of the network: the transactions entering
+// the mempool, the blocks mined and the payments received by wallets
+package watcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/tx"
+)
+
+// MempoolCaller is the part of the api client used by the MempoolWatcher
+type MempoolCaller interface {
+	GetPendingTransactions(ctx context.Context) ([]string, error)
+	GetUnconfirmedTransaction(ctx context.Context, txID string) (*tx.Transaction, error)
+	GetTransactionStatus(ctx context.Context, txID string) (*api.TransactionStatus, error)
+}
+
+// Filter selects transactions. Every non empty field must match
+type Filter struct {
+	Owners  []string // Addresses of the senders, one of which must match
+	Targets []string // Addresses of the recipients, one of which must match
+	Tags    []tx.Tag // Tags the transaction must all have, an empty value matching any value
+}
+
+// Match returns true if the transaction matches the filter
+func (f *Filter) Match(t *tx.Transaction) bool {
+	if len(f.Owners) > 0 && !contains(f.Owners, t.OwnerAddress()) {
+		return false
+	}
+	if len(f.Targets) > 0 && !contains(f.Targets, t.Target()) {
+		return false
+	}
+	if len(f.Tags) == 0 {
+		return true
+	}
+	tags, err := t.Tags()
+	if err != nil {
+		return false
+	}
+	for _, want := range f.Tags {
+		found := false
+		for _, tag := range tags {
+			if tag.Name == want.Name && (want.Value == "" || tag.Value == want.Value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// MempoolEventType is the kind of change seen in the mempool
+type MempoolEventType string
+
+const (
+	// MempoolEntered is sent when a transaction matching the filter enters the mempool
+	MempoolEntered MempoolEventType = "entered"
+	// MempoolMined is sent when a transaction left the mempool to be included in a block
+	MempoolMined MempoolEventType = "mined"
+	// MempoolLeft is sent when a transaction left the mempool without being mined
+	MempoolLeft MempoolEventType = "left"
+	// MempoolError is sent when polling the node failed, polling goes on afterwards
+	MempoolError MempoolEventType = "error"
+)
+
+// MempoolEvent reports a change in the mempool
+type MempoolEvent struct {
+	Type   MempoolEventType
+	TxID   string
+	Tx     *tx.Transaction        // Nil on MempoolError
+	Status *api.TransactionStatus // Set on MempoolMined
+	Err    error                  // Set on MempoolError
+}
+
+// MempoolWatcher polls the pending transactions of a node and reports the ones
+// matching its filter entering and leaving the mempool
+type MempoolWatcher struct {
+	client   MempoolCaller
+	filter   Filter
+	interval time.Duration
+
+	watched map[string]*tx.Transaction // pending transactions matching the filter
+	order   []string                   // IDs of the watched transactions, in the order they entered
+	ignored map[string]bool            // pending transactions not matching it
+}
+
+// NewMempoolWatcher creates a watcher polling the node every interval, 1
+// second if 0. The transactions already pending are reported on the first poll
+func NewMempoolWatcher(client MempoolCaller, filter Filter, interval time.Duration) *MempoolWatcher {
+	if interval == 0 {
+		interval = time.Second
+	}
+	return &MempoolWatcher{
+		client:   client,
+		filter:   filter,
+		interval: interval,
+		watched:  map[string]*tx.Transaction{},
+		ignored:  map[string]bool{},
+	}
+}
+
+// Run polls the node until ctx is done, calling fn with every event
+func (m *MempoolWatcher) Run(ctx context.Context, fn func(MempoolEvent)) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		events, err := m.Poll(ctx)
+		if err != nil {
+			fn(MempoolEvent{Type: MempoolError, Err: err})
+		}
+		for _, e := range events {
+			fn(e)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll compares the pending transactions of the node with the ones of the
+// previous poll and returns the changes, the transactions leaving the mempool in
+// the order they entered it. Transactions which could not be fetched are
+// retried on the next poll
+func (m *MempoolWatcher) Poll(ctx context.Context) ([]MempoolEvent, error) {
+	pending, err := m.client.GetPendingTransactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]bool{}
+	for _, id := range pending {
+		current[id] = true
+	}
+
+	events := []MempoolEvent{}
+	for _, id := range m.order {
+		if current[id] {
+			continue
+		}
+		status, err := m.client.GetTransactionStatus(ctx, id)
+		if err != nil {
+			m.compactOrder()
+			return events, err
+		}
+		t := m.watched[id]
+		delete(m.watched, id)
+		if status.State == api.StatusMined {
+			events = append(events, MempoolEvent{Type: MempoolMined, TxID: id, Tx: t, Status: status})
+		} else {
+			events = append(events, MempoolEvent{Type: MempoolLeft, TxID: id, Tx: t, Status: status})
+		}
+	}
+	m.compactOrder()
+	for id := range m.ignored {
+		if !current[id] {
+			delete(m.ignored, id)
+		}
+	}
+
+	var fetchErr error
+	for _, id := range pending {
+		if m.watched[id] != nil || m.ignored[id] {
+			continue
+		}
+		t, err := m.client.GetUnconfirmedTransaction(ctx, id)
+		if err != nil {
+			fetchErr = err
+			continue
+		}
+		if !m.filter.Match(t) {
+			m.ignored[id] = true
+			continue
+		}
+		m.watched[id] = t
+		m.order = append(m.order, id)
+		events = append(events, MempoolEvent{Type: MempoolEntered, TxID: id, Tx: t})
+	}
+	return events, fetchErr
+}
+
+// compactOrder drops the IDs of the transactions not watched anymore from the order
+func (m *MempoolWatcher) compactOrder() {
+	order := make([]string, 0, len(m.watched))
+	for _, id := range m.order {
+		if m.watched[id] != nil {
+			order = append(order, id)
+		}
+	}
+	m.order = order
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
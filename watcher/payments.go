@@ -0,0 +1,265 @@
+package watcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/tx"
+)
+
+// PaymentCaller is the part of the api client used by the PaymentWatcher
+type PaymentCaller interface {
+	GetInfo(ctx context.Context) (*api.NetworkInfo, error)
+	QueryTransactions(ctx context.Context, q *api.TransactionQuery) *api.TransactionIterator
+}
+
+// Payment is a transfer of AR to a watched address
+type Payment struct {
+	TxID          string
+	From          string
+	To            string
+	Amount        arweave.Amount
+	Memo          string // Value of the memo tag, if the transaction has one
+	Tags          []tx.Tag
+	Height        int64
+	BlockID       string
+	Confirmations int64 // At the time the payment was found
+}
+
+// PaymentCursor is the progress of a PaymentWatcher: the payments of the blocks
+// below Height were handled, as well as the ones of Handled, by ID with their
+// height, in the blocks from Height, which are scanned again
+type PaymentCursor struct {
+	Height  int64            `json:"height"`
+	Handled map[string]int64 `json:"handled,omitempty"`
+}
+
+// CursorStore persists the cursor of a PaymentWatcher
+type CursorStore interface {
+	// Load returns the cursor saved, nil if none was
+	Load() (*PaymentCursor, error)
+	Save(c *PaymentCursor) error
+}
+
+// FileCursorStore stores the cursor as JSON in a file
+type FileCursorStore struct {
+	Path string
+}
+
+// Load reads the cursor from the file, nil if the file does not exist
+func (s *FileCursorStore) Load() (*PaymentCursor, error) {
+	b, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &PaymentCursor{}
+	err = json.Unmarshal(b, c)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Save writes the cursor to a temporary file renamed to the file, so that a
+// crash never leaves a partially written cursor
+func (s *FileCursorStore) Save(c *PaymentCursor) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(b)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// PaymentWatcher reports the AR transferred to a set of addresses, using the
+// gateway's graphql endpoint. Each payment is reported once its block has enough
+// confirmations, and only once, the cursor being saved after every payment
+type PaymentWatcher struct {
+	// MemoTag is the name of the tag giving the memo of a payment, "Memo" by default
+	MemoTag string
+	// Confirmations a block needs before its payments are reported, 1 by default
+	Confirmations int64
+	// Interval between two polls of Run, 1 minute by default
+	Interval time.Duration
+	// RescanDepth is the number of the last heights scanned again on the next
+	// poll, for the payments the gateway indexes late, 1 by default
+	RescanDepth int64
+	// OnError is optional, it receives the errors of the polls of Run, which are
+	// retried on the next poll
+	OnError func(error)
+
+	client    PaymentCaller
+	addresses []string
+	store     CursorStore
+	cursor    *PaymentCursor
+}
+
+// NewPaymentWatcher creates a watcher of the payments to the addresses. Without a
+// saved cursor, it starts from the blocks mined after its first poll. At least
+// one address is needed: without one the gateway would return every transfer of
+// the network
+func NewPaymentWatcher(client PaymentCaller, addresses []string, store CursorStore) (*PaymentWatcher, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("no address to watch")
+	}
+	for _, address := range addresses {
+		if address == "" {
+			return nil, errors.New("empty address to watch")
+		}
+	}
+	return &PaymentWatcher{
+		MemoTag:       "Memo",
+		Confirmations: 1,
+		Interval:      time.Minute,
+		RescanDepth:   1,
+		client:        client,
+		addresses:     append([]string{}, addresses...),
+		store:         store,
+	}, nil
+}
+
+// Run polls for payments until ctx is done or fn returns an error, calling fn
+// with each payment. Polling errors are passed to OnError and retried on the
+// next poll
+func (p *PaymentWatcher) Run(ctx context.Context, fn func(Payment) error) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+	for {
+		var fnErr error
+		err := p.Poll(ctx, func(payment Payment) error {
+			fnErr = fn(payment)
+			return fnErr
+		})
+		if fnErr != nil {
+			return fnErr
+		}
+		if err != nil && ctx.Err() == nil && p.OnError != nil {
+			p.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll calls fn with the payments found since the previous poll, in order of
+// height. A payment is not reported again once fn returned without error for it,
+// even when its height is scanned again. Polling stops at the first error of fn
+// and returns it
+func (p *PaymentWatcher) Poll(ctx context.Context, fn func(Payment) error) error {
+	info, err := p.client.GetInfo(ctx)
+	if err != nil {
+		return err
+	}
+	current := int64(info.Height)
+	confirmations := p.Confirmations
+	if confirmations < 1 {
+		confirmations = 1
+	}
+	max := current - confirmations + 1
+
+	if p.cursor == nil {
+		p.cursor, err = p.store.Load()
+		if err != nil {
+			return err
+		}
+		if p.cursor == nil {
+			p.cursor = &PaymentCursor{Height: max + 1}
+			err = p.store.Save(p.cursor)
+			if err != nil {
+				return err
+			}
+		}
+		if p.cursor.Handled == nil {
+			p.cursor.Handled = map[string]int64{}
+		}
+	}
+	if p.cursor.Height > max {
+		return nil
+	}
+
+	q := api.NewTransactionQuery().
+		Recipients(p.addresses...).
+		BlockRange(p.cursor.Height, max).
+		Sort(api.HeightAsc).
+		PageSize(100)
+	it := p.client.QueryTransactions(ctx, q)
+	for it.Next() {
+		t := it.Transaction()
+		if t.Block == nil || t.Quantity.Winston.Sign() <= 0 {
+			continue
+		}
+		if _, ok := p.cursor.Handled[t.ID]; ok {
+			continue
+		}
+		payment := Payment{
+			TxID:          t.ID,
+			From:          t.Owner.Address,
+			To:            t.Recipient,
+			Amount:        t.Quantity.Winston,
+			Tags:          t.Tags,
+			Height:        t.Block.Height,
+			BlockID:       t.Block.ID,
+			Confirmations: current - t.Block.Height + 1,
+		}
+		for _, tag := range t.Tags {
+			if tag.Name == p.MemoTag {
+				payment.Memo = tag.Value
+			}
+		}
+		err = fn(payment)
+		if err != nil {
+			return err
+		}
+		p.cursor.Handled[t.ID] = t.Block.Height
+		err = p.store.Save(p.cursor)
+		if err != nil {
+			return err
+		}
+	}
+	if it.Err() != nil {
+		return it.Err()
+	}
+
+	// the last heights are scanned again on the next poll, only the payments
+	// handled in them are kept to skip them
+	next := max + 1
+	if p.RescanDepth > 0 {
+		next -= p.RescanDepth
+	}
+	if next > p.cursor.Height {
+		p.cursor.Height = next
+	}
+	for id, height := range p.cursor.Handled {
+		if height < p.cursor.Height {
+			delete(p.cursor.Handled, id)
+		}
+	}
+	return p.store.Save(p.cursor)
+}
@@ -0,0 +1,347 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dev43/arweave-go"
+	"github.com/Dev43/arweave-go/api"
+	"github.com/Dev43/arweave-go/arweavetest"
+	"github.com/Dev43/arweave-go/transactor"
+	"github.com/Dev43/arweave-go/tx"
+	"github.com/Dev43/arweave-go/wallet"
+	"github.com/stretchr/testify/assert"
+)
+
+var ctx = context.TODO()
+
+const deposit = "1seRanklLU_1VTGkEk7P0xAwMJfA7owA1JHW5KyZKlY"
+
+func helperSetup(t *testing.T) (*arweavetest.Node, *api.Client, func(b func(*transactor.TransactionBuilder)) *tx.Transaction) {
+	w := wallet.NewWallet()
+	err := w.LoadKeyFromFile(filepath.Join("..", "wallet", "testdata", "arweave-test.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := arweavetest.NewNode()
+	node.SetBalance(w.Address(), arweave.NewWinston(1000000000))
+	tr, err := transactor.NewTransactor(node.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(build func(*transactor.TransactionBuilder)) *tx.Transaction {
+		b := tr.NewTransactionBuilder(w)
+		build(b)
+		txn, err := b.Build(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txn, err = txn.Sign(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tr.SendTransaction(ctx, txn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txn
+	}
+	return node, tr.Client.(*api.Client), send
+}
+
+func TestMempoolWatcher(t *testing.T) {
+	node, c, send := helperSetup(t)
+	defer node.Close()
+
+	payment := send(func(b *transactor.TransactionBuilder) { b.Target(deposit).Quantity(arweave.NewWinston(100)) })
+	send(func(b *transactor.TransactionBuilder) { b.Data([]byte("unrelated")) })
+
+	m := NewMempoolWatcher(c, Filter{Targets: []string{deposit}}, 0)
+	events, err := m.Poll(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, MempoolEntered, events[0].Type)
+		assert.Equal(t, payment.Hash(), events[0].TxID)
+	}
+
+	dropped := send(func(b *transactor.TransactionBuilder) { b.Target(deposit).Quantity(arweave.NewWinston(200)) })
+	events, err = m.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	node.Drop(dropped.Hash(), "Evicted.")
+	node.Mine(1)
+	events, err = m.Poll(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		// in the order the transactions entered the mempool
+		assert.Equal(t, payment.Hash(), events[0].TxID)
+		assert.Equal(t, MempoolMined, events[0].Type)
+		assert.Equal(t, dropped.Hash(), events[1].TxID)
+		assert.Equal(t, MempoolLeft, events[1].Type)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	txn := tx.NewTransaction("", nil, arweave.NewWinston(1), deposit, nil, arweave.NewWinston(1))
+	txn.AddTag("App-Name", "shop")
+	cases := []struct {
+		filter Filter
+		match  bool
+	}{
+		{Filter{}, true},
+		{Filter{Targets: []string{deposit}}, true},
+		{Filter{Targets: []string{"other"}}, false},
+		{Filter{Tags: []tx.Tag{{Name: "App-Name"}}}, true},
+		{Filter{Tags: []tx.Tag{{Name: "App-Name", Value: "shop"}}}, true},
+		{Filter{Tags: []tx.Tag{{Name: "App-Name", Value: "other"}}}, false},
+		{Filter{Targets: []string{deposit}, Tags: []tx.Tag{{Name: "Content-Type"}}}, false},
+	}
+	for i, c := range cases {
+		assert.Equal(t, c.match, c.filter.Match(txn), "case %d", i)
+	}
+}
+
+func TestBlockFollower(t *testing.T) {
+	node, c, send := helperSetup(t)
+	defer node.Close()
+
+	f := NewBlockFollower(c, 0, 0)
+	heights := func(events []BlockEvent) []int64 {
+		h := []int64{}
+		for _, e := range events {
+			assert.Equal(t, BlockNew, e.Type)
+			h = append(h, e.Block.Height)
+		}
+		return h
+	}
+	events, err := f.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{0}, heights(events))
+
+	// the blocks mined between two polls are backfilled
+	txn := send(func(b *transactor.TransactionBuilder) { b.Data([]byte("hello")) })
+	node.Mine(3)
+	events, err = f.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, heights(events))
+	orphaned := []*api.Block{events[0].Block, events[1].Block, events[2].Block}
+
+	// the transaction of the orphaned blocks is mined again on the fork
+	node.Fork(3)
+	events, err = f.Poll(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 5) {
+		assert.Equal(t, BlockReorg, events[0].Type)
+		assert.Equal(t, orphaned, events[0].Orphaned)
+		assert.Equal(t, []int64{1, 2, 3, 4}, heights(events[1:]))
+		assert.Equal(t, orphaned[0].PreviousBlock, events[1].Block.PreviousBlock)
+		assert.Equal(t, []string{txn.Hash()}, events[1].Block.Txs)
+	}
+	assert.Equal(t, int64(5), f.Next())
+
+	events, err = f.Poll(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+// staticBlocks serves a fixed chain
+type staticBlocks []*api.Block
+
+func (s staticBlocks) GetInfo(ctx context.Context) (*api.NetworkInfo, error) {
+	return &api.NetworkInfo{Height: len(s) - 1}, nil
+}
+
+func (s staticBlocks) GetBlockByHeight(ctx context.Context, height int64) (*api.Block, error) {
+	return s[height], nil
+}
+
+func TestBlockFollowerInconsistentNode(t *testing.T) {
+	// the second block does not point to the first one, which stays canonical
+	blocks := staticBlocks{
+		{Height: 0, IndepHash: "a"},
+		{Height: 1, IndepHash: "b", PreviousBlock: "other"},
+	}
+	f := NewBlockFollower(blocks, 0, 0)
+	done := make(chan error)
+	go func() {
+		events, err := f.Poll(ctx)
+		assert.Len(t, events, 1)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("polling an inconsistent node does not return")
+	}
+	assert.Equal(t, int64(1), f.Next(), "the block is polled again")
+}
+
+func TestPaymentWatcher(t *testing.T) {
+	node, c, send := helperSetup(t)
+	defer node.Close()
+	dir, err := ioutil.TempDir("", "payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := &FileCursorStore{Path: filepath.Join(dir, "cursor.json")}
+
+	p, err := NewPaymentWatcher(c, []string{deposit}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Confirmations = 2
+	payments := []Payment{}
+	record := func(payment Payment) error {
+		payments = append(payments, payment)
+		return nil
+	}
+	assert.NoError(t, p.Poll(ctx, record))
+
+	first := send(func(b *transactor.TransactionBuilder) {
+		b.Target(deposit).Quantity(arweave.NewWinston(100)).Tag("Memo", "order-1")
+	})
+	send(func(b *transactor.TransactionBuilder) { b.Data([]byte("not a payment")) })
+	node.Mine(1)
+	assert.NoError(t, p.Poll(ctx, record))
+	assert.Empty(t, payments, "the block needs 2 confirmations")
+
+	second := send(func(b *transactor.TransactionBuilder) { b.Target(deposit).Quantity(arweave.NewWinston(200)) })
+	third := send(func(b *transactor.TransactionBuilder) { b.Target(deposit).Quantity(arweave.NewWinston(300)) })
+	node.Mine(1)
+	assert.NoError(t, p.Poll(ctx, record))
+	if assert.Len(t, payments, 1) {
+		assert.Equal(t, first.Hash(), payments[0].TxID)
+		assert.Equal(t, first.OwnerAddress(), payments[0].From)
+		assert.Equal(t, "100", payments[0].Amount.String())
+		assert.Equal(t, "order-1", payments[0].Memo)
+		assert.Equal(t, int64(2), payments[0].Confirmations)
+	}
+
+	// the handler fails in the middle of a block, a new watcher resumes after
+	// the payments handled
+	node.Mine(1)
+	failing := errors.New("failing")
+	err = p.Poll(ctx, func(payment Payment) error {
+		if payment.TxID == third.Hash() {
+			return failing
+		}
+		return record(payment)
+	})
+	assert.Equal(t, failing, err)
+	p, err = NewPaymentWatcher(c, []string{deposit}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Confirmations = 2
+	assert.NoError(t, p.Poll(ctx, record))
+	assert.NoError(t, p.Poll(ctx, record))
+	ids := []string{}
+	for _, payment := range payments {
+		ids = append(ids, payment.TxID)
+	}
+	assert.Equal(t, []string{first.Hash(), second.Hash(), third.Hash()}, ids)
+}
+
+func TestPaymentWatcherLateIndexing(t *testing.T) {
+	node, c, send := helperSetup(t)
+	defer node.Close()
+	dir, err := ioutil.TempDir("", "payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := &FileCursorStore{Path: filepath.Join(dir, "cursor.json")}
+
+	p, err := NewPaymentWatcher(c, []string{deposit}, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payments := []Payment{}
+	record := func(payment Payment) error {
+		payments = append(payments, payment)
+		return nil
+	}
+	assert.NoError(t, p.Poll(ctx, record))
+
+	first := send(func(b *transactor.TransactionBuilder) { b.Target(deposit).Quantity(arweave.NewWinston(100)) })
+	node.Mine(1)
+	// the gateway has not indexed the block yet
+	node.AddFault(arweavetest.Fault{
+		Method: "POST",
+		Path:   "graphql",
+		Times:  1,
+		Status: 200,
+		Body:   `{"data":{"transactions":{"pageInfo":{"hasNextPage":false},"edges":[]}}}`,
+	})
+	assert.NoError(t, p.Poll(ctx, record))
+	assert.Empty(t, payments)
+	assert.NoError(t, p.Poll(ctx, record))
+	assert.NoError(t, p.Poll(ctx, record))
+	if assert.Len(t, payments, 1, "the last height is scanned again, without repeating its payments") {
+		assert.Equal(t, first.Hash(), payments[0].TxID)
+	}
+
+	node.Mine(1)
+	assert.NoError(t, p.Poll(ctx, record))
+	assert.Len(t, payments, 1)
+	cursor, err := store.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cursor.Height)
+	assert.Empty(t, cursor.Handled, "the payments below the cursor are forgotten")
+}
+
+func TestPaymentWatcherRunErrors(t *testing.T) {
+	node, c, _ := helperSetup(t)
+	defer node.Close()
+	node.AddFault(arweavetest.Fault{Method: "GET", Path: "info", Status: 503})
+	dir, err := ioutil.TempDir("", "payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p, err := NewPaymentWatcher(c, []string{deposit}, &FileCursorStore{Path: filepath.Join(dir, "cursor.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Interval = time.Millisecond
+	errs := make(chan error, 10)
+	p.OnError = func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		done <- p.Run(runCtx, func(Payment) error { return nil })
+	}()
+	select {
+	case err := <-errs:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the polling errors are not reported")
+	}
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+}
+
+func TestPaymentWatcherNoAddress(t *testing.T) {
+	node, c, _ := helperSetup(t)
+	defer node.Close()
+	store := &FileCursorStore{Path: filepath.Join(os.TempDir(), "unused.json")}
+	for _, addresses := range [][]string{nil, {}, {deposit, ""}} {
+		p, err := NewPaymentWatcher(c, addresses, store)
+		assert.Error(t, err, "%q", addresses)
+		assert.Nil(t, p)
+	}
+}